@@ -0,0 +1,126 @@
+package vt
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	// activityMut guards the variables below
+	activityMut sync.Mutex
+
+	// lastActivity is when input last arrived on any TTY
+	lastActivity = time.Now()
+
+	// idle is true after an idle hook has fired, until input arrives
+	idle bool
+
+	idleHooks     []*idleHook
+	activityHooks []*func()
+)
+
+// idleHook is an idle callback registered with OnIdle
+type idleHook struct {
+	d     time.Duration
+	fn    func()
+	timer *time.Timer
+	start time.Time // when the hook was registered
+	fired bool      // fired since the last activity
+}
+
+// check fires the hook if there has been no input for h.d, or else waits
+// for the remaining time
+func (h *idleHook) check() {
+	activityMut.Lock()
+	if !slices.Contains(idleHooks, h) || h.fired {
+		activityMut.Unlock()
+		return
+	}
+	since := lastActivity
+	if h.start.After(since) {
+		since = h.start
+	}
+	if elapsed := time.Since(since); elapsed < h.d {
+		h.timer.Reset(h.d - elapsed)
+		activityMut.Unlock()
+		return
+	}
+	h.fired = true
+	idle = true
+	activityMut.Unlock()
+	h.fn()
+}
+
+// OnIdle calls fn once no input has arrived for the duration d, counting
+// from when OnIdle is called at the earliest. After input arrives again, fn
+// is called again the next time the input has been idle for d. The returned
+// function removes the hook. The timer does not keep the program from
+// exiting.
+func OnIdle(d time.Duration, fn func()) (cancel func()) {
+	h := &idleHook{d: d, fn: fn, start: time.Now()}
+	activityMut.Lock()
+	idleHooks = append(idleHooks, h)
+	h.timer = time.AfterFunc(d, h.check)
+	activityMut.Unlock()
+	return func() {
+		activityMut.Lock()
+		defer activityMut.Unlock()
+		h.timer.Stop()
+		idleHooks = slices.DeleteFunc(idleHooks, func(other *idleHook) bool { return other == h })
+	}
+}
+
+// OnActivity calls fn when input arrives after an idle period, that is,
+// after a hook registered with OnIdle has fired. The returned function
+// removes the hook.
+func OnActivity(fn func()) (cancel func()) {
+	p := &fn
+	activityMut.Lock()
+	activityHooks = append(activityHooks, p)
+	activityMut.Unlock()
+	return func() {
+		activityMut.Lock()
+		defer activityMut.Unlock()
+		activityHooks = slices.DeleteFunc(activityHooks, func(other *func()) bool { return other == p })
+	}
+}
+
+// noteActivity records that input arrived, re-arms the idle hooks that
+// have fired and calls the activity hooks if the input was idle
+func noteActivity(now time.Time) {
+	activityMut.Lock()
+	lastActivity = now
+	wasIdle := idle
+	idle = false
+	for _, h := range idleHooks {
+		if h.fired {
+			h.fired = false
+			h.timer.Reset(h.d)
+		}
+	}
+	var hooks []*func()
+	if wasIdle {
+		hooks = slices.Clone(activityHooks)
+	}
+	activityMut.Unlock()
+	for _, fn := range hooks {
+		(*fn)()
+	}
+}
+
+// LastActivity returns when input last arrived on this TTY, or the zero
+// time if no input has arrived yet
+func (tty *TTY) LastActivity() time.Time {
+	if ns := tty.lastActivity.Load(); ns != 0 {
+		return time.Unix(0, ns)
+	}
+	return time.Time{}
+}
+
+// markActivity is called whenever input bytes have been read from the TTY
+func (tty *TTY) markActivity() {
+	now := time.Now()
+	tty.lastActivity.Store(now.UnixNano())
+	noteActivity(now)
+}
@@ -0,0 +1,54 @@
+package vt
+
+import "strings"
+
+// TextAttr is a set of text attributes for a canvas cell, like bold or
+// underline, that are drawn in addition to the colors of the cell
+type TextAttr uint8
+
+// Text attributes, which can be combined with |
+const (
+	AttrBold TextAttr = 1 << iota
+	AttrDim
+	AttrItalic
+	AttrUnderline
+	AttrBlink
+	AttrReverse
+	AttrStrikethrough
+)
+
+// attrCodes are the SGR codes that turn on each text attribute, in the
+// order of the bits
+var attrCodes = [...]string{"1", "2", "3", "4", "5", "7", "9"}
+
+// String returns the escape sequence that turns on the attributes, or an
+// empty string if there are none or if NO_COLOR is set
+func (a TextAttr) String() string {
+	if a == 0 || EnvNoColor {
+		return ""
+	}
+	codes := make([]string, 0, len(attrCodes))
+	for i, code := range attrCodes {
+		if a&(1<<i) != 0 {
+			codes = append(codes, code)
+		}
+	}
+	return "\033[" + strings.Join(codes, ";") + "m"
+}
+
+// attrReset returns the escape sequence that turns off the attributes in
+// a, as well as bold, dim, italic and underline, without changing the
+// colors
+func attrReset(a TextAttr) string {
+	s := "\033[22;23;24"
+	if a&AttrBlink != 0 {
+		s += ";25"
+	}
+	if a&AttrReverse != 0 {
+		s += ";27"
+	}
+	if a&AttrStrikethrough != 0 {
+		s += ";29"
+	}
+	return s + "m"
+}
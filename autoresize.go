@@ -0,0 +1,95 @@
+package vt
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// resizeDebounce is how long AutoResizeCanvas waits for the terminal size
+// to settle after a resize signal, since resizing a window by dragging it
+// sends many signals in a short time
+const resizeDebounce = 50 * time.Millisecond
+
+// AutoResizeCanvas keeps track of a canvas that is replaced with a resized
+// one whenever the terminal is resized, see NewAutoResizeCanvas
+type AutoResizeCanvas struct {
+	mut       sync.Mutex
+	canvas    *Canvas
+	sigChan   chan os.Signal
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+// NewAutoResizeCanvas creates a canvas that is sized to the terminal, and
+// starts watching for terminal resizes. When the terminal has been resized,
+// a new canvas with the new size and the contents of the old one is
+// created, and onResize is called with it, from another goroutine, together
+// with the old and the new size. Bursts of resize signals are combined, so
+// that onResize is only called once the size has settled for 50ms. Call
+// Close to stop watching. Resizes are only detected on Unix-like systems.
+func NewAutoResizeCanvas(onResize func(c *Canvas, oldW, oldH, newW, newH uint)) *AutoResizeCanvas {
+	sigChan := make(chan os.Signal, 1)
+	SetupResizeHandler(sigChan)
+	return startAutoResize(NewCanvas(), sigChan, MustTermSize, onResize)
+}
+
+// startAutoResize starts replacing c when a signal arrives on sigChan and
+// termSize reports a new size
+func startAutoResize(c *Canvas, sigChan chan os.Signal, termSize func() (uint, uint), onResize func(c *Canvas, oldW, oldH, newW, newH uint)) *AutoResizeCanvas {
+	a := &AutoResizeCanvas{
+		canvas:  c,
+		sigChan: sigChan,
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go func() {
+		defer close(a.stopped)
+		debounce := time.NewTimer(resizeDebounce)
+		debounce.Stop()
+		defer debounce.Stop()
+		for {
+			select {
+			case <-a.done:
+				return
+			case <-sigChan:
+				debounce.Reset(resizeDebounce)
+			case <-debounce.C:
+				a.mut.Lock()
+				old := a.canvas
+				oldW, oldH := old.Size()
+				newW, newH := termSize()
+				nc := old.resizedTo(newW, newH)
+				if nc != nil {
+					a.canvas = nc
+				}
+				a.mut.Unlock()
+				if nc != nil && onResize != nil {
+					onResize(nc, oldW, oldH, newW, newH)
+				}
+			}
+		}
+	}()
+	return a
+}
+
+// Canvas returns the current canvas, which is replaced when the terminal
+// is resized
+func (a *AutoResizeCanvas) Canvas() *Canvas {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+	return a.canvas
+}
+
+// Close stops watching for terminal resizes, and waits until onResize is
+// no longer being called, so it must not be called from within onResize.
+// Closing more than once has no further effect.
+func (a *AutoResizeCanvas) Close() {
+	a.closeOnce.Do(func() {
+		signal.Stop(a.sigChan)
+		close(a.done)
+		<-a.stopped
+	})
+}
@@ -0,0 +1,40 @@
+package vt
+
+// BadgeSpec describes a single badge for DrawBadgeRow
+type BadgeSpec struct {
+	Label, Value     string
+	LabelFg, LabelBg AttributeColor
+	ValueFg, ValueBg AttributeColor
+	Style            BoxStyle
+}
+
+// DrawBadge draws a compact status badge at (x, y), similar to the badges
+// used on CI dashboards: " label │ value ", where the label part is drawn
+// with labelFg/labelBg and the value part with valueFg/valueBg. The
+// separator is the vertical line rune of the given style. Wide runes count
+// as two columns and combining marks as none.
+// Returns the total column width of the badge.
+func (c *Canvas) DrawBadge(x, y uint, label, value string, labelFg, labelBg, valueFg, valueBg AttributeColor, style BoxStyle) uint {
+	style = style.orDefault()
+	pos := x
+	put := func(fg, bg AttributeColor, s string) {
+		pos += c.writeColumns(pos, y, fg, bg, s)
+	}
+	put(labelFg, labelBg, " "+label+" "+string(style.VLeft))
+	put(valueFg, valueBg, " "+value+" ")
+	return pos - x
+}
+
+// DrawBadgeRow draws the given badges from left to right on row y, starting
+// at column x, with a one column gap between each badge.
+// Returns the total column width of the row.
+func (c *Canvas) DrawBadgeRow(x, y uint, badges []BadgeSpec) uint {
+	pos := x
+	for i, b := range badges {
+		if i > 0 {
+			pos++ // gap
+		}
+		pos += c.DrawBadge(pos, y, b.Label, b.Value, b.LabelFg, b.LabelBg, b.ValueFg, b.ValueBg, b.Style)
+	}
+	return pos - x
+}
@@ -0,0 +1,39 @@
+package vt
+
+import "testing"
+
+func TestDrawBadge(t *testing.T) {
+	c := NewCanvasWithSize(20, 1)
+	n := c.DrawBadge(0, 0, "ci", "ok", White, Blue, Black, Green, BoxStyleASCII)
+	if n != 9 {
+		t.Errorf("width: got %d, want 9", n)
+	}
+	if got, want := c.String(), " ci | ok            \n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestDrawBadgeWideRunes(t *testing.T) {
+	c := NewCanvasWithSize(12, 1)
+	n := c.DrawBadge(0, 0, "日本", "e\u0301", White, Blue, Black, Green, BoxStyleASCII)
+	if n != 10 {
+		t.Errorf("width: got %d, want 10", n)
+	}
+	if got, want := c.String(), " 日 本  | e   \n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestDrawBadgeRow(t *testing.T) {
+	c := NewCanvasWithSize(20, 1)
+	n := c.DrawBadgeRow(0, 0, []BadgeSpec{
+		{Label: "a", Value: "1", Style: BoxStyleASCII},
+		{Label: "b", Value: "2"},
+	})
+	if n != 15 {
+		t.Errorf("width: got %d, want 15", n)
+	}
+	if got, want := c.String(), " a | 1   b │ 2      \n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,174 @@
+package vt
+
+// BlitMode decides how the cells of the source canvas are combined with the
+// cells of the destination canvas by BlitWithMode
+type BlitMode int
+
+const (
+	// OpaqueCopy copies every source cell, replacing the destination cell
+	OpaqueCopy BlitMode = iota
+	// SkipTransparent copies the source cells, except for cells that were
+	// never written to (the rune is 0), which leave the destination cell
+	// untouched
+	SkipTransparent
+	// TintBackground only applies the background color of the source cells,
+	// keeping the runes and foreground colors of the destination, for
+	// instance for a tinted overlay that still shows the text beneath
+	TintBackground
+)
+
+// Blit copies the cells of src onto the canvas, with the top left corner of
+// src at (x, y), clipping at the edges. The copied cells are redrawn by the
+// next Draw. A wide rune that would be cut in half at the right edge is not
+// copied.
+func (c *Canvas) Blit(src *Canvas, x, y uint) {
+	c.BlitWithMode(src, x, y, OpaqueCopy)
+}
+
+// BlitTransparent is like Blit, but cells of src that were never written to
+// leave the canvas untouched, so that widgets with holes, sprites or dialog
+// boxes can be drawn over existing content. With any of the Blit methods, a
+// Transparent foreground or background color in src keeps the color of the
+// canvas, so that for instance text can be drawn on top of the background
+// beneath it.
+func (c *Canvas) BlitTransparent(src *Canvas, x, y uint) {
+	c.BlitWithMode(src, x, y, SkipTransparent)
+}
+
+// BlitMasked copies only the cells of src that have a rune, leaving the
+// rest of the canvas untouched, for drawing sprites or dialog boxes over
+// existing content. It is the same as BlitTransparent.
+func (c *Canvas) BlitMasked(src *Canvas, x, y uint) {
+	c.BlitWithMode(src, x, y, SkipTransparent)
+}
+
+// BlitWithMode is like Blit, but the cells are combined according to mode
+func (c *Canvas) BlitWithMode(src *Canvas, x, y uint, mode BlitMode) {
+	c.blitRegion(src, 0, 0, ^uint(0), ^uint(0), x, y, mode)
+}
+
+// BlitRegion copies the w x h region of src with its top left corner at
+// (srcX, srcY) onto the canvas, with the top left corner of the region at
+// (dstX, dstY). The region is clipped to the bounds of both canvases, and
+// the copied cells are redrawn by the next Draw. Half of a wide rune that
+// is cut off at the edges of the region is copied as a space, and a wide
+// rune of the canvas that is partly overwritten has its other half
+// replaced with a space. A wide rune that would be cut in half at the right
+// edge of the canvas is not copied.
+func (c *Canvas) BlitRegion(src *Canvas, srcX, srcY, w, h, dstX, dstY uint) {
+	c.blitRegion(src, srcX, srcY, w, h, dstX, dstY, OpaqueCopy)
+}
+
+// blitRegion is the shared implementation of BlitWithMode and BlitRegion
+func (c *Canvas) blitRegion(src *Canvas, srcX, srcY, w, h, dstX, dstY uint, mode BlitMode) {
+	cells, sw, sh := src.snapshotCells()
+	if srcX >= sw || srcY >= sh {
+		return
+	}
+	w, h = umin(w, sw-srcX), umin(h, sh-srcY)
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	for sy := range h {
+		dy := dstY + sy
+		if dy >= c.h {
+			break
+		}
+		prevWide := false // the previous cell was copied as a wide rune
+		for sx := range w {
+			dx := dstX + sx
+			if dx >= c.w {
+				break
+			}
+			cr := cells[(srcY+sy)*sw+srcX+sx]
+			index := dy*c.w + dx
+			if mode == TintBackground {
+				if c.inClip(dx, dy) && cr.bg != Transparent {
+					c.chars[index].bg = cr.bg
+					c.chars[index].drawn = false
+				}
+				continue
+			}
+			wide := prevWide
+			prevWide = false
+			if mode == SkipTransparent && cr.cw == 0 && cr.r == 0 {
+				continue
+			}
+			if cr.cw == 2 && dx+1 >= c.w {
+				break // only half of the wide rune would fit
+			}
+			if cr.cw == 2 && sx+1 >= w || cr.cw == 1 && !wide {
+				// The other half of the wide rune is not copied
+				cr = ColorRune{fg: cr.fg, bg: cr.bg, r: ' '}
+			}
+			if !c.inClip(dx, dy) || cr.cw == 2 && !c.inClip(dx+1, dy) {
+				continue
+			}
+			keepColors(&cr, c.chars[index])
+			cr.drawn = false
+			c.replaceCell(index, cr)
+			prevWide = cr.cw == 2
+		}
+	}
+}
+
+// NewLayer creates a w x h canvas where all cells are transparent, for
+// stacking on top of other canvases with Composite. A cell stays
+// transparent until something is written to it.
+func NewLayer(w, h uint) *Canvas {
+	c := NewCanvasWithSize(w, h)
+	clear(c.chars)
+	return c
+}
+
+// Composite draws the given layers onto the canvas, from the bottom layer
+// to the top one, with their top left corners at (0, 0). Transparent cells
+// of the layers, where the rune and both colors are zero, as they are in a
+// new layer, leave the cells below untouched. Only the cells that end up
+// different are redrawn by the next Draw.
+func (c *Canvas) Composite(layers ...*Canvas) {
+	for _, layer := range layers {
+		cells, lw, lh := layer.snapshotCells()
+		c.mut.Lock()
+		for y := range umin(lh, c.h) {
+			for x := range umin(lw, c.w) {
+				cr := cells[y*lw+x]
+				if cr == (ColorRune{}) || !c.inClip(x, y) || cr.cw == 2 && !c.inClip(x+1, y) {
+					continue
+				}
+				if cr.cw == 2 && x+1 >= c.w {
+					break // only half of the wide rune would fit
+				}
+				keepColors(&cr, c.chars[y*c.w+x])
+				cr.drawn = false
+				c.replaceCell(y*c.w+x, cr)
+			}
+		}
+		c.mut.Unlock()
+	}
+}
+
+// replaceCell places cr at index. When only one half of a wide rune of the
+// canvas is overwritten, the other half is replaced with a space. The canvas
+// mutex must be held.
+func (c *Canvas) replaceCell(index uint, cr ColorRune) {
+	old := c.chars[index]
+	c.chars[index] = cr
+	x := index % c.w
+	if old.cw == 2 && cr.cw != 2 && x+1 < c.w && c.chars[index+1].cw == 1 {
+		c.chars[index+1] = ColorRune{fg: old.fg, bg: old.bg, r: ' '}
+	}
+	if old.cw == 1 && cr.cw != 1 && x > 0 && c.chars[index-1].cw == 2 {
+		c.chars[index-1] = ColorRune{fg: c.chars[index-1].fg, bg: c.chars[index-1].bg, r: ' '}
+	}
+}
+
+// keepColors replaces the Transparent colors of cr with the colors of the
+// cell beneath
+func keepColors(cr *ColorRune, beneath ColorRune) {
+	if cr.fg == Transparent {
+		cr.fg = beneath.fg
+	}
+	if cr.bg == Transparent {
+		cr.bg = beneath.bg
+	}
+}
@@ -0,0 +1,159 @@
+package vt
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBlit(t *testing.T) {
+	src := NewCanvasWithSize(4, 1)
+	src.WriteRune(0, 0, Red, Blue, 'a')
+	src.WriteWideRuneB(2, 0, Red, Blue.Background(), '日')
+	for _, tc := range []struct {
+		mode BlitMode
+		want string
+	}{
+		{OpaqueCopy, "xa 日"},
+		{SkipTransparent, "xaz日"},
+		{TintBackground, "xyzw "},
+	} {
+		dst := NewCanvasWithSize(5, 1)
+		dst.WriteString(0, 0, Green, Default, "xyzw")
+		dst.BlitWithMode(src, 1, 0, tc.mode)
+		got := ""
+		for _, cr := range dst.Row(0) {
+			switch {
+			case cr.cw == 1:
+			case cr.r == 0:
+				got += " "
+			default:
+				got += string(cr.r)
+			}
+		}
+		if got != tc.want {
+			t.Errorf("mode %d: got %q, want %q", tc.mode, got, tc.want)
+		}
+		if tc.mode == TintBackground {
+			if cr := dst.chars[1]; cr.r != 'y' || cr.fg != Green || cr.bg != Blue.Background() {
+				t.Errorf("expected only the background to be applied, got %+v", cr)
+			}
+		}
+	}
+
+	// A wide rune that would be cut in half at the right edge is not copied
+	dst := NewCanvasWithSize(4, 1)
+	dst.WriteString(0, 0, Default, Default, "wxyz")
+	dst.Blit(src, 1, 0)
+	if r, _ := dst.At(3, 0); r != 'z' {
+		t.Errorf("got %q at the right edge, want the original 'z'", r)
+	}
+	if r, _ := dst.At(1, 0); r != 'a' {
+		t.Errorf("got %q, want 'a'", r)
+	}
+
+	// BlitTransparent leaves the cells that were never written to untouched
+	dst = NewCanvasWithSize(4, 1)
+	dst.WriteString(0, 0, Default, Default, "wxyz")
+	dst.BlitTransparent(src, 0, 0)
+	if r, _ := dst.At(1, 0); r != 'x' {
+		t.Errorf("got %q, want the original 'x'", r)
+	}
+}
+
+func TestComposite(t *testing.T) {
+	bg := NewCanvasWithSize(4, 2)
+	bg.WriteString(0, 0, Default, Default, "....")
+	bg.WriteString(0, 1, Default, Default, "....")
+	popup := NewLayer(3, 2)
+	popup.WriteString(1, 0, Red, Blue, "ab")
+	top := NewLayer(6, 1)
+	top.WriteBackground(0, 0, BackgroundGreen)
+
+	var buf strings.Builder
+	bg.SetOutput(&buf)
+	bg.Draw()
+	bg.Composite(popup, top)
+	if got, want := bg.String(), " ab.\n....\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if cr := bg.chars[0]; cr.bg != BackgroundGreen || cr.r != 0 {
+		t.Errorf("expected a cell with only a background to be opaque, got %+v", cr)
+	}
+	buf.Reset()
+	if !bg.Draw() || strings.Contains(buf.String(), "\033[2;1H") {
+		t.Errorf("expected only the changed row to be drawn, got %q", buf.String())
+	}
+}
+
+func TestCompositeWideRunes(t *testing.T) {
+	c := NewCanvasWithSize(6, 1)
+	c.WriteString(0, 0, Default, Default, "日ab本")
+	layer := NewLayer(6, 1)
+	layer.WriteRune(1, 0, Red, Blue, 'x')
+	layer.WriteRune(4, 0, Red, Blue, 'y')
+	c.Composite(layer)
+	if got, want := c.String(), " xaby \n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	for i, cr := range c.Row(0) {
+		if cr.cw == 1 || cr.cw == 2 {
+			t.Errorf("cell %d: got %+v, want no halves of wide runes left", i, cr)
+		}
+	}
+}
+
+func TestBlitRegion(t *testing.T) {
+	src := NewCanvasWithSize(6, 2)
+	src.WriteString(0, 0, Red, Blue, "日本ab")
+	src.WriteString(0, 1, Red, Blue, "cdefgh")
+	dst := NewCanvasWithSize(5, 3)
+	dst.WriteString(0, 0, Default, Default, "語xyz")
+	// Both halves of the wide runes are cut off by the region
+	dst.BlitRegion(src, 1, 0, 2, 5, 1, 0)
+	if got, want := dst.String(), "   yz\n de  \n     \n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	for i, cr := range dst.Row(0) {
+		if cr.cw == 1 && (i == 0 || dst.Row(0)[i-1].cw != 2) {
+			t.Errorf("dangling continuation cell at %d", i)
+		}
+	}
+	if cr := dst.chars[dst.w+1]; cr.fg != Red || cr.bg != Blue.Background() || cr.drawn {
+		t.Errorf("got %+v, want a red on blue cell that is not drawn", cr)
+	}
+}
+
+func TestBlitTransparentColors(t *testing.T) {
+	src := NewCanvasWithSize(3, 1)
+	src.WriteRune(0, 0, Red, Transparent, 'a')
+	src.WriteRune(2, 0, Transparent, Green, 'c')
+	dst := NewCanvasWithSize(3, 1)
+	dst.WriteString(0, 0, Blue, Yellow, "xyz")
+	dst.BlitTransparent(src, 0, 0)
+	masked := NewCanvasWithSize(3, 1)
+	masked.WriteString(0, 0, Blue, Yellow, "xyz")
+	masked.BlitMasked(src, 0, 0)
+	if got, want := masked.Row(0), dst.Row(0); !slices.Equal(got, want) {
+		t.Errorf("expected BlitMasked to be the same as BlitTransparent, got %+v, want %+v", got, want)
+	}
+	row := dst.Row(0)
+	if row[0].r != 'a' || row[0].fg != Red || row[0].bg != Yellow.Background() {
+		t.Errorf("expected the background to be kept, got %+v", row[0])
+	}
+	if row[1].r != 'y' || row[1].fg != Blue {
+		t.Errorf("expected the empty cell to leave the canvas untouched, got %+v", row[1])
+	}
+	if row[2].r != 'c' || row[2].fg != Blue || row[2].bg != Green.Background() {
+		t.Errorf("expected the foreground to be kept, got %+v", row[2])
+	}
+	if Transparent.String() != "" {
+		t.Errorf("Transparent should have no escape sequence, got %q", Transparent.String())
+	}
+	if got := Transparent.Combine(Red); got != Red {
+		t.Errorf("expected Transparent to be left out when combining, got %d", got)
+	}
+	if got := Red.Combine(Transparent); got != Red {
+		t.Errorf("expected Transparent to be left out when combining, got %d", got)
+	}
+}
@@ -0,0 +1,268 @@
+package vt
+
+// BoxStyle holds the runes used for drawing box borders and separators
+type BoxStyle struct {
+	TL, TR, BL, BR rune // corners: top left, top right, bottom left, bottom right
+	HTop, HBot     rune // horizontal lines at the top and at the bottom
+	VLeft, VRight  rune // vertical lines at the left and at the right
+}
+
+// Predefined box styles
+var (
+	BoxStyleSingle  = BoxStyle{'┌', '┐', '└', '┘', '─', '─', '│', '│'}
+	BoxStyleRounded = BoxStyle{'╭', '╮', '╰', '╯', '─', '─', '│', '│'}
+	BoxStyleDouble  = BoxStyle{'╔', '╗', '╚', '╝', '═', '═', '║', '║'}
+	BoxStyleHeavy   = BoxStyle{'┏', '┓', '┗', '┛', '━', '━', '┃', '┃'}
+	BoxStyleASCII   = BoxStyle{'+', '+', '+', '+', '-', '-', '|', '|'}
+)
+
+// orDefault returns s, or BoxStyleSingle if s is the zero value
+func (s BoxStyle) orDefault() BoxStyle {
+	if s == (BoxStyle{}) {
+		return BoxStyleSingle
+	}
+	return s
+}
+
+// DrawBox draws the border of a w x h box with its top left corner at
+// (x, y). The inside of the box is left untouched. A zero style draws
+// single lines. A box that is only one cell high or wide is drawn as a
+// line, and the parts of the box that are outside of the canvas are
+// clipped.
+func (c *Canvas) DrawBox(x, y, w, h uint, style BoxStyle, fg, bg AttributeColor) {
+	style = style.orDefault()
+	switch {
+	case w == 0 || h == 0:
+		return
+	case h == 1:
+		for i := range w {
+			c.WriteRune(x+i, y, fg, bg, style.HTop)
+		}
+		return
+	case w == 1:
+		for j := range h {
+			c.WriteRune(x, y+j, fg, bg, style.VLeft)
+		}
+		return
+	}
+	right, bottom := x+w-1, y+h-1
+	for i := x + 1; i < right; i++ {
+		c.WriteRune(i, y, fg, bg, style.HTop)
+		c.WriteRune(i, bottom, fg, bg, style.HBot)
+	}
+	for j := y + 1; j < bottom; j++ {
+		c.WriteRune(x, j, fg, bg, style.VLeft)
+		c.WriteRune(right, j, fg, bg, style.VRight)
+	}
+	c.WriteRune(x, y, fg, bg, style.TL)
+	c.WriteRune(right, y, fg, bg, style.TR)
+	c.WriteRune(x, bottom, fg, bg, style.BL)
+	c.WriteRune(right, bottom, fg, bg, style.BR)
+}
+
+// Rect draws the border of a w x h rectangle with its top left corner at
+// (x, y), like DrawBox, but with the arguments in the same order as
+// FillRect
+func (c *Canvas) Rect(x, y, w, h uint, fg, bg AttributeColor, style BoxStyle) {
+	c.DrawBox(x, y, w, h, style, fg, bg)
+}
+
+// DrawFilledBox is like DrawBox, but also fills the inside of the box with
+// spaces in the given colors
+func (c *Canvas) DrawFilledBox(x, y, w, h uint, style BoxStyle, fg, bg AttributeColor) {
+	if w > 2 && h > 2 {
+		c.fillRect(x+1, y+1, w-2, h-2, fg, bg)
+	}
+	c.DrawBox(x, y, w, h, style, fg, bg)
+}
+
+// DrawTitledBox draws a box like DrawBox, with the title centered in the
+// top border, surrounded by a space on each side. A title that is too wide
+// for the box is truncated with '…'.
+func (c *Canvas) DrawTitledBox(x, y, w, h uint, fg, bg AttributeColor, style BoxStyle, title string) {
+	c.DrawBox(x, y, w, h, style, fg, bg)
+	if title == "" || w < 5 || h < 2 {
+		return
+	}
+	title = " " + truncate(title, int(w-4)) + " "
+	c.WriteString(x+(w-uint(VisibleWidth(title)))/2, y, fg, bg, title)
+}
+
+// drawTitledFrame draws a frame with the title written into the top
+// border, starting two columns in. The title is truncated to fit.
+func (c *Canvas) drawTitledFrame(x, y, w, h uint, style BoxStyle, fg, bg AttributeColor, title string, titleFg AttributeColor) {
+	c.DrawBox(x, y, w, h, style, fg, bg)
+	if title == "" || w < 5 {
+		return
+	}
+	c.WriteString(x+2, y, titleFg, bg, truncate(title, int(w-4)))
+}
+
+// FillRect fills the w x h rectangle with its top left corner at (x, y)
+// with r, in the given colors. The parts of the rectangle that are outside
+// of the canvas are clipped.
+func (c *Canvas) FillRect(x, y, w, h uint, fg, bg AttributeColor, r rune) {
+	c.FillRegion(x, y, w, h, fg, bg, r)
+}
+
+// FillRegion fills the w x h rectangle with its top left corner at (x, y)
+// with r, in the given colors, under one lock. The parts of the rectangle
+// that are outside of the canvas are clipped. A wide rune that is only
+// partly within the rectangle is cleared as a whole, as by ClearRegion.
+func (c *Canvas) FillRegion(x, y, w, h uint, fg, bg AttributeColor, r rune) {
+	bg = bg.Background()
+	c.fillRegion(x, y, w, h, true, func(cr *ColorRune) {
+		cr.r, cr.fg, cr.bg, cr.cw, cr.attr = r, fg, bg, 0, 0
+	})
+}
+
+// FillBackgroundRegion sets the background color of the w x h rectangle
+// with its top left corner at (x, y), while leaving the runes and the
+// foreground colors as they are. This is useful for highlighting a
+// selection or for drawing a shadow.
+func (c *Canvas) FillBackgroundRegion(x, y, w, h uint, bg AttributeColor) {
+	bg = bg.Background()
+	c.fillRegion(x, y, w, h, false, func(cr *ColorRune) {
+		cr.bg = bg
+	})
+}
+
+// FillBackgroundRegionAddRuneIfEmpty is like FillBackgroundRegion, but
+// also places r in the cells of the rectangle that are empty
+func (c *Canvas) FillBackgroundRegionAddRuneIfEmpty(x, y, w, h uint, bg AttributeColor, r rune) {
+	bg = bg.Background()
+	c.fillRegion(x, y, w, h, false, func(cr *ColorRune) {
+		cr.bg = bg
+		if cr.r == 0 {
+			cr.r = r
+		}
+	})
+}
+
+// fillRegion calls fill for each cell of the w x h rectangle with its top
+// left corner at (x, y) that is within the canvas and the clip rectangle,
+// and marks the cell as not drawn. If replace is true, fill replaces the
+// runes, so the other halves of wide runes that are cut by the edges of the
+// rectangle are cleared.
+func (c *Canvas) fillRegion(x, y, w, h uint, replace bool, fill func(cr *ColorRune)) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	for j := y; j < umin(y+h, c.h); j++ {
+		for i := x; i < umin(x+w, c.w); i++ {
+			px, py, ok := c.padded(i, j)
+			if !ok || !c.inClip(px, py) {
+				continue
+			}
+			if replace {
+				c.clearCutWideRune(py*c.w+px, i == x, i+1 == x+w)
+			}
+			cr := &c.chars[py*c.w+px]
+			fill(cr)
+			cr.drawn = false
+		}
+	}
+}
+
+// FloodFill replaces the rune and colors of all cells that can be reached
+// from (x, y), moving up, down, left or right, and that have the same rune
+// and foreground color as the cell at (x, y). The fill stays within the
+// canvas, the padding and the clip rectangle. The continuation cells of
+// wide runes are passed over, and when a wide rune is replaced, its
+// continuation cell becomes a space.
+func (c *Canvas) FloodFill(x, y uint, fg, bg AttributeColor, r rune) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	px, py, ok := c.padded(x, y)
+	if !ok || !c.inClip(px, py) || c.chars[py*c.w+px].cw == 1 {
+		return
+	}
+	target := c.chars[py*c.w+px]
+	bg = bg.Background()
+	minX, minY := c.padLeft, c.padTop
+	maxX, maxY := c.w-c.padRight, c.h-c.padBottom
+	visited := make([]bool, len(c.chars))
+	stack := []uint{py*c.w + px}
+	for len(stack) > 0 {
+		index := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[index] {
+			continue
+		}
+		visited[index] = true
+		i, j := index%c.w, index/c.w
+		cr := &c.chars[index]
+		if cr.cw == 1 || cr.r != target.r || !cr.fg.Equal(target.fg) || !c.inClip(i, j) {
+			continue
+		}
+		if cr.cw == 2 && i+1 < c.w {
+			next := &c.chars[index+1]
+			next.r, next.fg, next.bg, next.cw, next.attr, next.drawn = ' ', fg, bg, 0, 0, false
+		}
+		cr.r, cr.fg, cr.bg, cr.cw, cr.attr, cr.drawn = r, fg, bg, 0, 0, false
+		if i > minX {
+			stack = append(stack, index-1)
+		}
+		if i+1 < maxX {
+			stack = append(stack, index+1)
+		}
+		if j > minY {
+			stack = append(stack, index-c.w)
+		}
+		if j+1 < maxY {
+			stack = append(stack, index+c.w)
+		}
+	}
+}
+
+// ClearRegion clears the w x h rectangle with its top left corner at
+// (x, y), so that its cells are empty and have the default colors again,
+// for instance when dismissing a popup. The parts of the rectangle that are
+// outside of the canvas are clipped. A wide rune that is only partly within
+// the rectangle is cleared as a whole.
+func (c *Canvas) ClearRegion(x, y, w, h uint) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	for j := y; j < umin(y+h, c.h); j++ {
+		for i := x; i < umin(x+w, c.w); i++ {
+			px, py, ok := c.padded(i, j)
+			if !ok || !c.inClip(px, py) {
+				continue
+			}
+			index := py*c.w + px
+			c.clearCutWideRune(index, i == x, i+1 == x+w)
+			c.chars[index] = blankCell
+		}
+	}
+}
+
+// clearCutWideRune clears the other half of the wide rune at index, if it
+// is cut by the left edge of a region, when first is true, or by the right
+// edge, when last is true. The canvas mutex must be held.
+func (c *Canvas) clearCutWideRune(index uint, first, last bool) {
+	switch cr := c.chars[index]; {
+	case cr.cw == 1 && first && index%c.w > 0:
+		c.chars[index-1] = blankCell
+	case cr.cw == 2 && last && index%c.w+1 < c.w:
+		c.chars[index+1] = blankCell
+	}
+}
+
+// fillRect fills a w x h region with spaces in the given colors
+func (c *Canvas) fillRect(x, y, w, h uint, fg, bg AttributeColor) {
+	c.FillRect(x, y, w, h, fg, bg, ' ')
+}
+
+// CenteredBox returns the top left position of a w x h box centered on the
+// canvas. If the box is larger than the canvas, it is placed at 0 along that
+// axis, so that the top left corner stays on screen.
+func (c *Canvas) CenteredBox(w, h uint) (uint, uint) {
+	cw, ch := c.W(), c.H()
+	var x, y uint
+	if w < cw {
+		x = (cw - w) / 2
+	}
+	if h < ch {
+		y = (ch - h) / 2
+	}
+	return x, y
+}
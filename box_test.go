@@ -0,0 +1,271 @@
+package vt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCenteredBox(t *testing.T) {
+	c := NewCanvasWithSize(80, 24)
+	for _, tc := range []struct {
+		w, h, x, y uint
+	}{
+		{20, 10, 30, 7},
+		{80, 24, 0, 0},
+		{21, 5, 29, 9},
+		{100, 30, 0, 0},
+		{0, 0, 40, 12},
+	} {
+		if x, y := c.CenteredBox(tc.w, tc.h); x != tc.x || y != tc.y {
+			t.Errorf("CenteredBox(%d, %d) = (%d, %d), want (%d, %d)", tc.w, tc.h, x, y, tc.x, tc.y)
+		}
+	}
+}
+
+func TestDrawBoxCentered(t *testing.T) {
+	c := NewCanvasWithSize(10, 5)
+	x, y := c.CenteredBox(4, 3)
+	c.DrawBox(x, y, 4, 3, BoxStyleASCII, Default, DefaultBackground)
+	want := []string{
+		"          ",
+		"   +--+   ",
+		"   |  |   ",
+		"   +--+   ",
+		"          ",
+	}
+	for j, line := range want {
+		for i, r := range []rune(line) {
+			got, err := c.At(uint(i), uint(j))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r == ' ' && got == 0 {
+				continue
+			}
+			if got != r {
+				t.Errorf("at (%d, %d): got %q, want %q", i, j, got, r)
+			}
+		}
+	}
+}
+
+func TestDrawFilledBox(t *testing.T) {
+	c := NewCanvasWithSize(4, 4)
+	c.Plot(1, 1, 'x')
+	c.DrawFilledBox(0, 0, 3, 3, BoxStyleRounded, Red, Blue)
+	if r, _ := c.At(1, 1); r != ' ' {
+		t.Errorf("expected the inside to be filled, got %q", r)
+	}
+	if cr := c.chars[1*4+1]; cr.bg != Blue.Background() {
+		t.Errorf("expected the inside to get the background color, got %+v", cr)
+	}
+	if r, _ := c.At(2, 2); r != '╯' {
+		t.Errorf("got %q, want '╯'", r)
+	}
+
+	// A box that is partially outside of the canvas is clipped
+	c = NewCanvasWithSize(4, 4)
+	c.DrawBox(2, 2, 5, 5, BoxStyleASCII, Default, DefaultBackground)
+	if r, _ := c.At(2, 2); r != '+' {
+		t.Errorf("got %q, want '+'", r)
+	}
+	if r, _ := c.At(3, 2); r != '-' {
+		t.Errorf("got %q, want '-'", r)
+	}
+
+	// A box that is one cell high is drawn as a line
+	c = NewCanvasWithSize(4, 1)
+	c.DrawBox(0, 0, 3, 1, BoxStyleASCII, Default, DefaultBackground)
+	if got := c.String(); got != "--- \n" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestDrawTitledBox(t *testing.T) {
+	c := NewCanvasWithSize(10, 3)
+	c.DrawTitledBox(0, 0, 10, 3, Default, DefaultBackground, BoxStyleASCII, "ok")
+	if got, want := strings.SplitN(c.String(), "\n", 2)[0], "+-- ok --+"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	c.DrawTitledBox(0, 0, 10, 3, Default, DefaultBackground, BoxStyleASCII, "a long title")
+	if got, want := strings.SplitN(c.String(), "\n", 2)[0], "+ a lon… +"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	c.DrawTitledBox(0, 0, 10, 3, Default, DefaultBackground, BoxStyleASCII, "日本語のタイトル")
+	for x, want := range map[uint]rune{1: ' ', 2: '日', 4: '本', 6: '…', 7: ' ', 8: '-'} {
+		if got, _ := c.At(x, 0); got != want {
+			t.Errorf("at %d: got %q, want %q", x, got, want)
+		}
+	}
+}
+
+func TestFillRect(t *testing.T) {
+	c := NewCanvasWithSize(4, 3)
+	c.FillRect(2, 1, 10, 10, Red, Blue, '#')
+	if got, want := c.String(), "    \n  ##\n  ##\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if cr := c.chars[1*4+2]; cr.fg != Red || cr.bg != Blue.Background() {
+		t.Errorf("unexpected colors: %+v", cr)
+	}
+	c.FillRect(5, 5, 2, 2, Red, Blue, '#') // entirely outside, ignored
+}
+
+func TestDrawText(t *testing.T) {
+	c := NewCanvasWithSize(12, 1)
+	for _, tc := range []struct {
+		s     string
+		align Align
+		want  string
+	}{
+		{"ab", AlignLeft, "[ab    ]"},
+		{"ab", AlignCenter, "[  ab  ]"},
+		{"abc", AlignCenter, "[ abc  ]"},
+		{"ab", AlignRight, "[    ab]"},
+		{Red.Get("red"), AlignRight, "[   red]"},
+		{"too long text", AlignCenter, "[too l…]"},
+	} {
+		c.WriteString(0, 0, Default, DefaultBackground, "[xxxxxx]xxx")
+		c.DrawText(1, 0, 6, Default, DefaultBackground, tc.s, tc.align)
+		if got := strings.TrimRight(c.String(), "x \n"); got != tc.want {
+			t.Errorf("DrawText(%q, %d) = %q, want %q", tc.s, tc.align, got, tc.want)
+		}
+	}
+	c.DrawText(1, 0, 6, Default, DefaultBackground, "日本語です", AlignLeft)
+	if r, _ := c.At(7, 0); r != ']' {
+		t.Errorf("expected nothing to be written past the width, got %q", r)
+	}
+}
+
+func TestWriteCenteredAndRightAligned(t *testing.T) {
+	c := NewCanvasWithSize(8, 3)
+	c.WriteCentered(0, Default, DefaultBackground, "日本")
+	c.WriteRightAligned(1, 8, Default, DefaultBackground, "ab")
+	c.WriteRightAligned(2, 5, Default, DefaultBackground, "too long")
+	for _, tc := range []struct {
+		x, y uint
+		want rune
+	}{
+		{2, 0, '日'}, {4, 0, '本'}, {6, 0, 0},
+		{5, 1, 0}, {6, 1, 'a'}, {7, 1, 'b'},
+		{0, 2, 't'}, {4, 2, '…'}, {5, 2, 0},
+	} {
+		if got, _ := c.At(tc.x, tc.y); got != tc.want {
+			t.Errorf("at (%d, %d): got %q, want %q", tc.x, tc.y, got, tc.want)
+		}
+	}
+	c.WriteCentered(0, Default, DefaultBackground, "much too long")
+	if got := strings.SplitN(c.String(), "\n", 2)[0]; got != "much to…" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestClearRegion(t *testing.T) {
+	c := NewCanvasWithSize(6, 2)
+	c.WriteString(0, 0, Red, Blue, "日ab本")
+	c.WriteString(0, 1, Red, Blue, "xyzuvw")
+	c.ClearRegion(1, 0, 4, 5)
+	if got, want := c.String(), "      \nx    w\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	for i, cr := range c.Row(0) {
+		if cr != blankCell {
+			t.Errorf("cell %d: got %+v, want a blank cell", i, cr)
+		}
+	}
+	if cr := c.Row(1)[0]; cr.r != 'x' || cr.fg != Red {
+		t.Errorf("expected the cell outside of the region to be kept, got %+v", cr)
+	}
+}
+
+func TestFillRegionWideRunes(t *testing.T) {
+	c := NewCanvasWithSize(6, 1)
+	c.WriteString(0, 0, Red, Blue, "日ab本")
+	c.FillRegion(1, 0, 4, 1, Green, Default, 'x')
+	if got, want := c.String(), " xxxx \n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	row := c.Row(0)
+	for _, i := range []int{0, 5} {
+		if row[i] != blankCell {
+			t.Errorf("cell %d: got %+v, want the other half of the wide rune cleared", i, row[i])
+		}
+	}
+	for i, cr := range row[1:5] {
+		if cr.r != 'x' || cr.cw != 0 {
+			t.Errorf("cell %d: got %+v, want a filled cell", i+1, cr)
+		}
+	}
+}
+
+func TestFillBackgroundRegion(t *testing.T) {
+	c := NewCanvasWithSize(5, 3)
+	c.WriteString(0, 1, Red, DefaultBackground, "abc")
+	c.FillBackgroundRegion(1, 1, 10, 1, Blue)
+	if cr := c.chars[1*5+1]; cr.r != 'b' || !cr.fg.Equal(Red) || cr.bg != Blue.Background() {
+		t.Errorf("expected the rune and foreground to be kept, got %+v", cr)
+	}
+	if cr := c.chars[1*5+0]; cr.bg == Blue.Background() {
+		t.Errorf("expected the cell left of the region to be left alone, got %+v", cr)
+	}
+	if cr := c.chars[1*5+4]; cr.r != 0 || cr.bg != Blue.Background() {
+		t.Errorf("expected the region to be clipped at the right edge, got %+v", cr)
+	}
+
+	c.FillBackgroundRegionAddRuneIfEmpty(0, 0, 5, 2, Green, '.')
+	if got, want := c.String(), ".....\nabc..\n     \n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	c.FillRegion(3, 2, 2, 1, Red, Blue, '#')
+	if got, want := c.String(), ".....\nabc..\n   ##\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestFloodFill(t *testing.T) {
+	c := NewCanvasWithSize(7, 5)
+	// A box with a gap in the wall to the right of the top row
+	c.DrawBox(0, 0, 5, 4, BoxStyleASCII, Default, DefaultBackground)
+	c.Plot(4, 1, 0)
+	c.WriteString(1, 2, Red, DefaultBackground, "x")
+	c.FloodFill(1, 1, Blue, Green, '.')
+	want := "+---+..\n|......\n|x..|..\n+---+..\n.......\n"
+	if got := c.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if cr := c.chars[2*7+1]; cr.r != 'x' || !cr.fg.Equal(Red) {
+		t.Errorf("expected a cell with another color to be left intact, got %+v", cr)
+	}
+	if cr := c.chars[1*7+2]; !cr.fg.Equal(Blue) || cr.bg != Green.Background() {
+		t.Errorf("expected the filled cells to get the new colors, got %+v", cr)
+	}
+
+	// Wide runes are replaced as a whole, and continuation cells are
+	// neither filled nor spread through
+	c = NewCanvasWithSize(4, 1)
+	c.WriteString(0, 0, Default, DefaultBackground, "日日")
+	c.FloodFill(1, 0, Default, DefaultBackground, '#')
+	if got := c.String(); got != "日 日 \n" {
+		t.Errorf("expected a fill from a continuation cell to do nothing, got %q", got)
+	}
+	c.FloodFill(0, 0, Default, DefaultBackground, '#')
+	if got := c.String(); got != "# 日 \n" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestRect(t *testing.T) {
+	c := NewCanvasWithSize(5, 4)
+	style := BoxStyle{TL: 'a', TR: 'b', BL: 'c', BR: 'd', HTop: 't', HBot: 'u', VLeft: 'l', VRight: 'r'}
+	c.Rect(0, 0, 4, 3, Default, DefaultBackground, style)
+	if got, want := c.String(), "attb \nl  r \ncuud \n     \n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	// Clipped at the edges of the canvas, without panicking
+	c = NewCanvasWithSize(3, 2)
+	c.Rect(1, 1, 10, 10, Default, DefaultBackground, BoxStyleDouble)
+	if got, want := c.String(), "   \n ╔═\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -3,8 +3,12 @@ package vt
 import (
 	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 )
 
 // umin returns the smaller of two uint values
@@ -21,7 +25,28 @@ type ColorRune struct {
 	bg    AttributeColor
 	r     rune
 	drawn bool
-	cw    uint8 // 0=normal, 1=continuation (skip), 2=wide (2-col)
+	cw    uint8    // 0=normal, 1=continuation (skip), 2=wide (2-col)
+	attr  TextAttr // bold, underline and so on, see WriteStringStyled
+}
+
+// Rune returns the rune of the cell
+func (cr ColorRune) Rune() rune {
+	return cr.r
+}
+
+// Fg returns the foreground color of the cell
+func (cr ColorRune) Fg() AttributeColor {
+	return cr.fg
+}
+
+// Bg returns the background color of the cell
+func (cr ColorRune) Bg() AttributeColor {
+	return cr.bg
+}
+
+// Attr returns the text attributes of the cell
+func (cr ColorRune) Attr() TextAttr {
+	return cr.attr
 }
 
 // Char is an alias for ColorRune, for API stability
@@ -38,6 +63,25 @@ type Canvas struct {
 	termCursorVisible bool // last state sent to terminal
 	lineWrap          bool
 	runewise          bool
+	padTop            uint
+	padRight          uint
+	padBottom         uint
+	padLeft           uint
+	dimmed            map[uint]ColorRune // original cells, by index, see DimRegion
+	cursorX           uint               // logical cursor position, see SetLogicalCursor
+	cursorY           uint
+	termX             uint // where the last Draw left the terminal cursor
+	termY             uint
+	showAt            bool // show the cursor at showAtX, showAtY after the next Draw
+	showAtX           uint
+	showAtY           uint
+	shownAt           bool        // the last Draw showed the cursor because of ShowCursorAt
+	out               io.Writer   // where the output goes, or nil for stdout
+	clip              *clipRect   // writes outside of it are discarded, see SetClip
+	clipStack         []*clipRect // saved by PushClip
+	generation        uint        // incremented when Resize reallocates the cells, see SubCanvas
+	statusLine        bool        // the last row is only drawn by SetStatus, see ReserveStatusLine
+	lastDrawStats     DrawStats   // see LastDrawStats
 }
 
 // canvasCopy is a Canvas without the mutex
@@ -50,6 +94,15 @@ type canvasCopy struct {
 	termCursorVisible bool
 	lineWrap          bool
 	runewise          bool
+	padTop            uint
+	padRight          uint
+	padBottom         uint
+	padLeft           uint
+	out               io.Writer
+	clip              *clipRect
+	clipStack         []*clipRect
+	statusLine        bool
+	dimmed            map[uint]ColorRune
 }
 
 // NewCanvas creates a canvas sized to the current terminal
@@ -67,6 +120,9 @@ func NewCanvas() *Canvas {
 	c.termCursorVisible = true // assume visible so flushCursor emits the hide escape
 	c.lineWrap = false
 	c.runewise = false // per-line positioning with synchronized output works correctly under multiplexers
+	if linearOutput.Load() {
+		return c
+	}
 	c.flushCursor()
 	c.SetLineWrap(c.lineWrap)
 	return c
@@ -87,6 +143,15 @@ func (c *Canvas) Copy() Canvas {
 		termCursorVisible: c.termCursorVisible,
 		lineWrap:          c.lineWrap,
 		runewise:          c.runewise,
+		padTop:            c.padTop,
+		padRight:          c.padRight,
+		padBottom:         c.padBottom,
+		padLeft:           c.padLeft,
+		out:               c.out,
+		clip:              c.clip,
+		clipStack:         slices.Clone(c.clipStack),
+		statusLine:        c.statusLine,
+		dimmed:            maps.Clone(c.dimmed),
 	}
 	copy(cc.chars, c.chars)
 	copy(cc.oldchars, c.oldchars)
@@ -100,6 +165,15 @@ func (c *Canvas) Copy() Canvas {
 		termCursorVisible: cc.termCursorVisible,
 		lineWrap:          cc.lineWrap,
 		runewise:          cc.runewise,
+		padTop:            cc.padTop,
+		padRight:          cc.padRight,
+		padBottom:         cc.padBottom,
+		padLeft:           cc.padLeft,
+		out:               cc.out,
+		clip:              cc.clip,
+		clipStack:         cc.clipStack,
+		statusLine:        cc.statusLine,
+		dimmed:            cc.dimmed,
 		mut:               &sync.RWMutex{},
 	}
 }
@@ -124,6 +198,75 @@ func (c *Canvas) Fill(fg AttributeColor) {
 	c.mut.Unlock()
 }
 
+// RemapColor changes the foreground and background colors that are equal
+// to from into to, for all cells. This can be used for switching themes
+// without drawing the contents again.
+func (c *Canvas) RemapColor(from, to AttributeColor) {
+	fromBg, toBg := from.Background(), to.Background()
+	c.mut.Lock()
+	for i := range c.chars {
+		cr := &c.chars[i]
+		if cr.fg.Equal(from) {
+			cr.fg = to
+			cr.drawn = false
+		}
+		if cr.bg.Equal(fromBg) {
+			cr.bg = toBg
+			cr.drawn = false
+		}
+	}
+	c.mut.Unlock()
+}
+
+// PulseRegion draws the canvas times times, alternating the background of
+// the w x h region at (x, y) between colorA and colorB, and waiting for
+// interval after each Draw. Then the original background colors are put
+// back and the canvas is drawn once more, so that only the region is
+// redrawn. This can be used for flashing an error field or a notification.
+func (c *Canvas) PulseRegion(x, y, w, h uint, colorA, colorB AttributeColor, times int, interval time.Duration) {
+	c.mut.Lock()
+	if x >= c.w || y >= c.h {
+		c.mut.Unlock()
+		return
+	}
+	w, h = umin(w, c.w-x), umin(h, c.h-y)
+	saved := make([]AttributeColor, 0, w*h)
+	for j := y; j < y+h; j++ {
+		for i := x; i < x+w; i++ {
+			saved = append(saved, c.chars[j*c.w+i].bg)
+		}
+	}
+	c.mut.Unlock()
+
+	setBackground := func(bg func(int) AttributeColor) {
+		c.mut.Lock()
+		defer c.mut.Unlock()
+		if c.w < x+w || c.h < y+h {
+			return // resized in the meantime
+		}
+		k := 0
+		for j := y; j < y+h; j++ {
+			for i := x; i < x+w; i++ {
+				c.chars[j*c.w+i].bg = bg(k)
+				c.chars[j*c.w+i].drawn = false
+				k++
+			}
+		}
+	}
+	for n := range times {
+		color := colorA
+		if n%2 == 1 {
+			color = colorB
+		}
+		color = color.Background()
+		setBackground(func(int) AttributeColor { return color })
+		c.Draw()
+		time.Sleep(interval)
+	}
+	setBackground(func(k int) AttributeColor { return saved[k] })
+	c.Draw()
+}
+
 // String returns only the characters, as a long string with a newline after each row
 func (c *Canvas) String() string {
 	var sb strings.Builder
@@ -143,12 +286,55 @@ func (c *Canvas) String() string {
 	return sb.String()
 }
 
+// ColorString is like String, but with the escape sequences for the colors
+// and the text attributes, the way Draw writes them: a color is only
+// emitted when it changes, also across rows, and the colors are reset at
+// the end. This is useful for logging a frame or for piping it to another
+// program. See also ExportANSI, which resets the colors at each row.
+func (c *Canvas) ColorString() string {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	return c.ansiString(false)
+}
+
+// StringTrimmed is like String, but trailing spaces are trimmed from each
+// row and blank rows at the end are left out
+func (c *Canvas) StringTrimmed() string {
+	var sb strings.Builder
+	blankRows := 0
+	c.mut.RLock()
+	for y := uint(0); y < c.h; y++ {
+		row := c.chars[y*c.w : (y+1)*c.w]
+		end := len(row)
+		for end > 0 && (row[end-1].r == rune(0) || row[end-1].r == ' ') {
+			end--
+		}
+		if end == 0 {
+			blankRows++
+			continue
+		}
+		sb.WriteString(strings.Repeat("\n", blankRows))
+		blankRows = 0
+		for _, cr := range row[:end] {
+			if cr.r == rune(0) {
+				sb.WriteRune(' ')
+			} else {
+				sb.WriteRune(cr.r)
+			}
+		}
+		sb.WriteRune('\n')
+	}
+	c.mut.RUnlock()
+	return sb.String()
+}
+
 // PlotAll tries to plot each individual rune.
 // It's very inefficient and meant to be used as a robust fallback.
 func (c *Canvas) PlotAll() {
 	w := c.w
 	h := c.h
 	c.mut.Lock()
+	out := c.out
 	for y := range h {
 		for x := int(w - 1); x >= 0; x-- {
 			cr := &((*c).chars[y*w+uint(x)])
@@ -159,11 +345,11 @@ func (c *Canvas) PlotAll() {
 			if cr.r == rune(0) {
 				r = ' '
 			}
-			SetXY(uint(x), y)
-			if uint32(cr.fg) < 256 && uint32(cr.bg) < 256 {
-				fmt.Print(cr.fg.Combine(cr.bg).String() + string(r) + envResetSeq)
+			setXYOn(out, uint(x), y)
+			if cr.fg < 256 && cr.bg < 256 {
+				writeOutput(out, cr.fg.Combine(cr.bg).String()+string(r)+envResetSeq)
 			} else {
-				fmt.Print(cr.fg.String() + cr.bg.String() + string(r) + envResetSeq)
+				writeOutput(out, cr.fg.String()+cr.bg.String()+string(r)+envResetSeq)
 			}
 		}
 	}
@@ -200,7 +386,14 @@ func (c *Canvas) SetLineWrap(enable bool) {
 	c.mut.Lock()
 	defer c.mut.Unlock()
 	c.lineWrap = enable
-	SetLineWrap(enable)
+	setLineWrapOn(c.out, enable)
+}
+
+// LineWrap returns true if line wrapping is enabled for this canvas
+func (c *Canvas) LineWrap() bool {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	return c.lineWrap
 }
 
 // SetShowCursor sets the cursor visibility
@@ -237,8 +430,9 @@ func (c *Canvas) flushCursor() {
 		return
 	}
 	c.termCursorVisible = desired
+	out := c.out
 	c.mut.Unlock()
-	ShowCursor(desired)
+	showCursorOn(out, desired)
 }
 
 // SetRunewise enables or disables per-rune rendering
@@ -248,6 +442,35 @@ func (c *Canvas) SetRunewise(b bool) {
 	c.runewise = b
 }
 
+// SetPaddingArea sets a margin around the canvas. Once set, the coordinates
+// given to the write methods (WriteRune, WriteString, Plot, PlotColor, ...)
+// are offset by (left, top), and writes that would land in the right or
+// bottom margin are clipped. This can be used to keep widgets away from the
+// outermost rows and columns. The unchecked WriteRuneB, WriteBackground and
+// *NoLock methods keep using absolute canvas coordinates.
+func (c *Canvas) SetPaddingArea(top, right, bottom, left uint) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.padTop = top
+	c.padRight = right
+	c.padBottom = bottom
+	c.padLeft = left
+}
+
+// ClearPaddingArea removes the margin set with SetPaddingArea
+func (c *Canvas) ClearPaddingArea() {
+	c.SetPaddingArea(0, 0, 0, 0)
+}
+
+// padded translates (x, y) from padded-area coordinates to canvas
+// coordinates, and reports if the result is within the padded area.
+// The canvas mutex must be held.
+func (c *Canvas) padded(x, y uint) (uint, uint, bool) {
+	x += c.padLeft
+	y += c.padTop
+	return x, y, x+c.padRight < c.w && y+c.padBottom < c.h
+}
+
 // W returns the canvas width
 func (c *Canvas) W() uint {
 	c.mut.RLock()
@@ -262,49 +485,131 @@ func (c *Canvas) H() uint {
 	return c.h
 }
 
-// DrawAndSetCursor draws the entire canvas and then places the cursor at x,y
+// DrawAndSetCursor draws the entire canvas and then places the cursor at x,y.
+// The position is also used as the logical cursor, see SetLogicalCursor.
 func (c *Canvas) DrawAndSetCursor(x, y uint) {
 	c.Draw()
-	SetXY(x, y)
+	c.SetLogicalCursor(x, y)
+	c.RestoreCursorToLogical()
+}
+
+// SetLogicalCursor sets where the application expects the terminal cursor
+// to be, for instance after a prompt. See RestoreCursorToLogical.
+func (c *Canvas) SetLogicalCursor(x, y uint) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.cursorX, c.cursorY = x, y
+}
+
+// LogicalCursor returns the position set with SetLogicalCursor
+func (c *Canvas) LogicalCursor() (uint, uint) {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	return c.cursorX, c.cursorY
+}
+
+// TerminalCursor returns where the terminal cursor was left by the last
+// Draw, or by RestoreCursorToLogical. Draw leaves the cursor after the
+// last cell it wrote, which is often the bottom right corner.
+func (c *Canvas) TerminalCursor() (uint, uint) {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	return c.termX, c.termY
+}
+
+// RestoreCursorToLogical moves the terminal cursor back to the position
+// set with SetLogicalCursor, so that output printed after a Draw appears
+// where the application expects it
+func (c *Canvas) RestoreCursorToLogical() {
+	c.mut.Lock()
+	x, y := c.cursorX, c.cursorY
+	c.termX, c.termY = x, y
+	out := c.out
+	c.mut.Unlock()
+	setXYOn(out, x, y)
 }
 
 // draw is the shared implementation for Draw and HideCursorAndDraw.
 // When permanentlyHideCursor is true, the cursor stays hidden after drawing.
-func (c *Canvas) draw(permanentlyHideCursor bool) {
-	c.mut.RLock()
+// Returns false if nothing was written, because nothing had changed.
+//
+// The canvas is locked from the moment the frame is built until oldchars has
+// been updated, so a concurrent Resize (or write) can never change the size
+// of chars under the frame builder, nor sneak undrawn cells into oldchars.
+// Only the final write to stdout happens without holding the lock.
+func (c *Canvas) draw(permanentlyHideCursor bool) bool {
+	c.mut.Lock()
 
 	if len((*c).chars) == 0 {
-		c.mut.RUnlock()
-		return
+		c.mut.Unlock()
+		return false
 	}
 
 	w := c.w
 	h := c.h
-	firstRun := len(c.oldchars) == 0
+	drawH := c.drawHeight()
+	firstRun := len(c.oldchars) != len(c.chars)
 	cursorVisible := c.cursorVisible
 	runewise := c.runewise
+	out := c.out
+	showAt, wasShownAt := c.showAt && !permanentlyHideCursor, c.shownAt
+	c.showAt, c.shownAt = false, showAt
+
+	var stats DrawStats
+	if !firstRun {
+		stats.CellsCompared = int(w * drawH)
+	}
 
 	// Quick change detection with early exit
 	if !firstRun {
 		skipAll := true
 		size := w*h - 1
+		if drawH < h {
+			size = w * drawH
+		}
 		for i := range size {
 			cr := (*c).chars[i]
 			if cr.cw == 1 {
 				continue
 			}
 			oldcr := (*c).oldchars[i]
-			if !cr.fg.Equal(oldcr.fg) || !cr.bg.Equal(oldcr.bg) || cr.r != oldcr.r {
+			if cr.needsDraw(oldcr) {
 				skipAll = false
 				break
 			}
 		}
 		if skipAll {
-			c.mut.RUnlock()
-			return
+			stats.Skipped = true
+			c.lastDrawStats = stats
+			switch {
+			case showAt:
+				x, y := c.showAtX, c.showAtY
+				c.termX, c.termY = x, y
+				c.termCursorVisible = true
+				c.mut.Unlock()
+				setXYOn(out, x, y)
+				showCursorOn(out, true)
+			case wasShownAt && !cursorVisible:
+				// Hide the cursor that was shown by ShowCursorAt
+				c.termCursorVisible = false
+				c.mut.Unlock()
+				showCursorOn(out, false)
+			default:
+				c.mut.Unlock()
+			}
+			return false
 		}
 	}
 
+	if linearOutput.Load() {
+		frame := c.linearFrame(firstRun)
+		stats.BytesWritten = len(frame)
+		c.lastDrawStats = stats
+		c.mut.Unlock()
+		writeOutput(out, frame)
+		return frame != ""
+	}
+
 	// Build the entire output in a single buffer
 	var sb strings.Builder
 	sb.Grow(int(w * h * 2))
@@ -314,10 +619,14 @@ func (c *Canvas) draw(permanentlyHideCursor bool) {
 	// Hide cursor while drawing to prevent flicker
 	sb.WriteString(hideCursor)
 
+	// Keep track of where the terminal cursor ends up
+	termX, termY := c.termX, c.termY
+
 	if runewise {
 		// Per-cell rendering with explicit positioning (robust fallback).
 		// Only rewrite cells that actually changed.
-		for y := range h {
+		var lastattr TextAttr
+		for y := range drawH {
 			base := y * w
 			for x := range w {
 				idx := base + x
@@ -325,12 +634,11 @@ func (c *Canvas) draw(permanentlyHideCursor bool) {
 					break // skip bottom-right corner to prevent scroll
 				}
 				cr := (*c).chars[idx]
-				if cr.cw == 1 {
+				if cr.cw == 1 || cr.drawn {
 					continue
 				}
 				if !firstRun {
-					oldcr := (*c).oldchars[idx]
-					if cr.fg.Equal(oldcr.fg) && cr.bg.Equal(oldcr.bg) && cr.r == oldcr.r {
+					if !cr.needsDraw((*c).oldchars[idx]) {
 						continue
 					}
 				}
@@ -338,20 +646,20 @@ func (c *Canvas) draw(permanentlyHideCursor bool) {
 				if r == 0 {
 					r = ' '
 				}
-				fmt.Fprintf(&sb, "\033[%d;%dH\033[22;23;24m", y+1, x+1)
-				if uint32(cr.fg) < 256 && uint32(cr.bg) < 256 {
-					sb.WriteString(cr.fg.Combine(cr.bg).String())
-				} else {
-					sb.WriteString(cr.fg.String() + cr.bg.String())
-				}
+				stats.CellsChanged++
+				fmt.Fprintf(&sb, "\033[%d;%dH%s", y+1, x+1, attrReset(lastattr))
+				writeCellColors(&sb, cr)
+				lastattr = cr.attr
 				sb.WriteRune(r)
+				termX, termY = x+1+uint(cr.cw/2), y
 			}
 		}
 	} else {
 		// Per-line differential rendering with explicit cursor positioning.
 		// Only lines with at least one changed cell are rewritten.
 		var lastfg, lastbg AttributeColor
-		for y := range h {
+		var lastattr TextAttr
+		for y := range drawH {
 			base := y * w
 			maxX := w
 			if y == h-1 {
@@ -366,7 +674,7 @@ func (c *Canvas) draw(permanentlyHideCursor bool) {
 						continue
 					}
 					oldcr := (*c).oldchars[base+x]
-					if !cr.fg.Equal(oldcr.fg) || !cr.bg.Equal(oldcr.bg) || cr.r != oldcr.r {
+					if cr.needsDraw(oldcr) {
 						lineChanged = true
 						break
 					}
@@ -388,24 +696,34 @@ func (c *Canvas) draw(permanentlyHideCursor bool) {
 			fmt.Fprintf(&sb, "\033[%d;1H\033[0m", y+1)
 			lastfg = Default
 			lastbg = Default
+			lastattr = 0
 
+			skipped := false
 			for x := range maxX {
 				cr := (*c).chars[base+x]
 				if cr.cw == 1 {
 					continue
 				}
-				if x == 0 || !lastfg.Equal(cr.fg) || !lastbg.Equal(cr.bg) {
+				if cr.drawn {
+					// Leave cells marked with MarkDrawn as they are
+					skipped = true
+					continue
+				}
+				if skipped {
+					fmt.Fprintf(&sb, "\033[%d;%dH", y+1, x+1)
+					skipped = false
+				}
+				if firstRun || cr.needsDraw((*c).oldchars[base+x]) {
+					stats.CellsChanged++
+				}
+				if x == 0 || !lastfg.Equal(cr.fg) || !lastbg.Equal(cr.bg) || lastattr != cr.attr {
 					if x > 0 {
 						// Reset bold/italic/underline so they don't bleed
 						// into the next cell. Cells that want them re-emit
 						// via their own SGR.
-						sb.WriteString("\033[22;23;24m")
-					}
-					if uint32(cr.fg) < 256 && uint32(cr.bg) < 256 {
-						sb.WriteString(cr.fg.Combine(cr.bg).String())
-					} else {
-						sb.WriteString(cr.fg.String() + cr.bg.String())
+						sb.WriteString(attrReset(lastattr))
 					}
+					writeCellColors(&sb, cr)
 				}
 				if cr.r != 0 {
 					sb.WriteRune(cr.r)
@@ -414,87 +732,171 @@ func (c *Canvas) draw(permanentlyHideCursor bool) {
 				}
 				lastfg = cr.fg
 				lastbg = cr.bg
+				lastattr = cr.attr
 			}
+			termX, termY = maxX, y
 		}
 	}
 
-	// Paint the bottom-right cell last with autowrap disabled. Writing a
-	// printable character into the last cell of a terminal with DECAWM
-	// (ESC [ ? 7) enabled would scroll the screen; the DECAWM-off / write /
-	// DECAWM-on dance avoids that and lets the status bar (or any other
-	// full-width painted row) occupy the entire bottom row. Only emit when
-	// the cell actually changed to keep diff-rendering efficient, and only
-	// when both dimensions are >= 1.
-	if w > 0 && h > 0 {
+	// Paint the bottom-right cell last, with autowrap disabled. Only emit
+	// it when the cell actually changed, to keep diff-rendering efficient.
+	if w > 0 && h > 0 && drawH == h {
 		lastIdx := w*h - 1
 		lastCR := (*c).chars[lastIdx]
-		if lastCR.cw != 1 {
-			emitLast := firstRun
-			if !firstRun {
-				oldLast := (*c).oldchars[lastIdx]
-				emitLast = !lastCR.fg.Equal(oldLast.fg) || !lastCR.bg.Equal(oldLast.bg) || lastCR.r != oldLast.r
-			}
-			if emitLast {
-				r := lastCR.r
-				if r == 0 {
-					r = ' '
-				}
-				// DECAWM off, move to (h, w), emit SGR + rune, DECAWM on.
-				sb.WriteString("\033[?7l")
-				fmt.Fprintf(&sb, "\033[%d;%dH", h, w)
-				if uint32(lastCR.fg) < 256 && uint32(lastCR.bg) < 256 {
-					sb.WriteString(lastCR.fg.Combine(lastCR.bg).String())
-				} else {
-					sb.WriteString(lastCR.fg.String() + lastCR.bg.String())
-				}
-				sb.WriteRune(r)
-				sb.WriteString("\033[?7h")
-			}
+		if lastCR.cw != 1 && !lastCR.drawn && (firstRun || lastCR.needsDraw((*c).oldchars[lastIdx])) {
+			c.writeLastCell(&sb, lastCR)
+			termX, termY = w, h-1
+			stats.CellsChanged++
 		}
 	}
 
-	// End synchronized update — terminal renders the buffered frame
-	sb.WriteString(endSyncUpdate)
+	if lc := len(c.chars); len(c.oldchars) != lc {
+		c.oldchars = make([]ColorRune, lc)
+	}
+	updateOldChars(c.oldchars, c.chars[:w*drawH])
 
-	c.mut.RUnlock()
+	return c.finishDraw(&sb, out, permanentlyHideCursor, cursorVisible, showAt, termX, termY, &stats)
+}
 
-	// Write the complete frame to stdout in a single call
-	writeAllToStdout([]byte(sb.String()))
+// writeCellColors writes the SGR sequence for the colors and the text
+// attributes of cr
+func writeCellColors(sb *strings.Builder, cr ColorRune) {
+	if cr.fg < 256 && cr.bg < 256 {
+		sb.WriteString(cr.fg.Combine(cr.bg).String())
+	} else {
+		sb.WriteString(cr.fg.String() + cr.bg.String())
+	}
+	if cr.attr != 0 {
+		sb.WriteString(cr.attr.String())
+	}
+}
+
+// needsDraw returns true if cr looks different from oldcr on the terminal,
+// and has not been marked as drawn with MarkDrawn
+func (cr ColorRune) needsDraw(oldcr ColorRune) bool {
+	return !cr.drawn && (!cr.fg.Equal(oldcr.fg) || !cr.bg.Equal(oldcr.bg) || cr.r != oldcr.r || cr.attr != oldcr.attr)
+}
+
+// unknownCell is stored in oldchars for cells that were left alone because
+// they were marked with MarkDrawn. It never matches a cell, since what the
+// terminal shows there is unknown.
+var unknownCell = ColorRune{r: -1}
+
+// updateOldChars copies the cells in chars to oldchars, which must have the
+// same length, except for the cells that are marked with MarkDrawn. Those
+// were not written to the terminal, so they are repainted once they are
+// written to again, even if they get back their earlier content.
+func updateOldChars(oldchars, chars []ColorRune) {
+	for i, cr := range chars {
+		if cr.drawn {
+			oldchars[i] = unknownCell
+		} else {
+			oldchars[i] = cr
+		}
+	}
+}
+
+// writeLastCell writes cr to the bottom-right cell of the canvas. Writing a
+// printable character into the last cell of a terminal with DECAWM
+// (ESC [ ? 7) enabled would scroll the screen; the DECAWM-off / write /
+// DECAWM-on dance avoids that and lets the status bar (or any other
+// full-width painted row) occupy the entire bottom row. The toggle is
+// scoped to this one cell: autowrap is only turned back on if it was on to
+// begin with, so the wrap mode chosen with SetLineWrap is left exactly as
+// it was. The canvas mutex must be held.
+func (c *Canvas) writeLastCell(sb *strings.Builder, cr ColorRune) {
+	r := cr.r
+	if r == 0 {
+		r = ' '
+	}
+	if c.lineWrap {
+		sb.WriteString(disableLineWrap)
+	}
+	// Reset the attributes first, so that those of other cells do not bleed
+	fmt.Fprintf(sb, "\033[%d;%dH\033[0m", c.h, c.w)
+	writeCellColors(sb, cr)
+	sb.WriteRune(r)
+	if c.lineWrap {
+		sb.WriteString(enableLineWrap)
+	}
+}
 
-	// Update internal state to match what was emitted.
+// finishDraw ends the synchronized update started in sb, updates the cursor
+// state, unlocks the canvas mutex, which must be held, and writes the frame
+// to out. (termX, termY) is where the frame leaves the terminal cursor.
+// If stats is not nil, it is stored as the statistics of the last Draw,
+// together with the size of the frame. Always returns true, since
+// something was written.
+func (c *Canvas) finishDraw(sb *strings.Builder, out io.Writer, permanentlyHideCursor, cursorVisible, showAt bool, termX, termY uint, stats *DrawStats) bool {
+	// End synchronized update — terminal renders the buffered frame
+	sb.WriteString(endSyncUpdate)
+
+	// Update internal state to match what is about to be emitted.
 	// Always treat termCursorVisible as false after drawing because the BSU block
 	// hides the cursor at the start and some terminals (e.g. Konsole) do not
 	// correctly apply cursor show/hide escapes emitted inside a BSU block.
 	// The explicit ShowCursor call below restores visibility outside BSU.
-	c.mut.Lock()
 	if permanentlyHideCursor {
 		c.cursorVisible = false
 		c.termCursorVisible = false
 	} else {
 		c.termCursorVisible = false
 	}
-	if lc := len(c.chars); len(c.oldchars) != lc {
-		c.oldchars = make([]ColorRune, lc)
+	// The cursor stays in the last column after writing to it
+	c.termX, c.termY = umin(termX, c.w-1), termY
+	if showAt {
+		c.termX, c.termY = c.showAtX, c.showAtY
+		c.termCursorVisible = true
+		fmt.Fprintf(sb, cursorHomeTemplate, c.showAtY+1, c.showAtX+1)
+	}
+	if stats != nil {
+		stats.BytesWritten = sb.Len()
+		c.lastDrawStats = *stats
 	}
-	copy(c.oldchars, c.chars)
 	c.mut.Unlock()
 
+	// Write the complete frame to stdout in a single call
+	writeOutput(out, sb.String())
+
+	if showAt {
+		showCursorOn(out, true)
+		return true
+	}
+
 	// Restore cursor visibility OUTSIDE the BSU block so that all terminals
 	// (including Konsole, which doesn't reliably handle cursor escapes inside BSU)
 	// correctly show the cursor after drawing.
 	if !permanentlyHideCursor && cursorVisible {
 		c.flushCursor()
 	}
+	return true
 }
 
-// Draw the entire canvas
-func (c *Canvas) Draw() {
-	c.draw(false)
+// ShowCursorAt places and shows the cursor at (x, y) after the next Draw,
+// for instance for a text cursor that is only shown while idle. The
+// cursor is hidden again by the following Draw, unless ShowCursorAt is
+// called again, or the cursor has been made visible with ShowCursor.
+func (c *Canvas) ShowCursorAt(x, y uint) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.showAt, c.showAtX, c.showAtY = true, x, y
 }
 
-// HideCursorAndDraw hides the cursor and draws the entire canvas
-func (c *Canvas) HideCursorAndDraw() {
-	c.draw(true)
+// Draw the entire canvas. Returns true if anything was written to the
+// terminal, or false if nothing had changed since the previous Draw.
+// Every row that is written starts with an explicit cursor position
+// (ESC[row;1H), or every cell when drawing runewise, so that the output
+// resynchronizes at the next row if a byte is lost on the way, for
+// instance over mosh. This is always the case, except in the linear output
+// mode, see SetLinearOutput.
+func (c *Canvas) Draw() bool {
+	return c.draw(false)
+}
+
+// HideCursorAndDraw hides the cursor and draws the entire canvas.
+// Returns true if anything was written to the terminal.
+func (c *Canvas) HideCursorAndDraw() bool {
+	return c.draw(true)
 }
 
 // Redraw marks all cells dirty and re-renders
@@ -560,13 +962,155 @@ func (c *Canvas) At(x, y uint) (rune, error) {
 	return chars[index].r, nil
 }
 
+// Cell describes the contents of a canvas cell. A wide rune takes up two
+// cells: the first one has Wide set, and the second one has Continuation
+// set and no rune of its own.
+type Cell struct {
+	R            rune
+	Fg           AttributeColor
+	Bg           AttributeColor
+	Wide         bool
+	Continuation bool
+	Attr         TextAttr
+}
+
+// CellAt returns the contents of the cell at (x, y)
+func (c *Canvas) CellAt(x, y uint) (Cell, error) {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	if x >= c.w || y >= c.h {
+		return Cell{}, errors.New("out of bounds")
+	}
+	cr := c.chars[y*c.w+x]
+	return Cell{R: cr.r, Fg: cr.fg, Bg: cr.bg, Wide: cr.cw == 2, Continuation: cr.cw == 1, Attr: cr.attr}, nil
+}
+
+// SetCell sets the contents of the cell at (x, y), for instance to restore
+// a cell that was read with CellAt. Setting a Wide cell also marks the cell
+// to the right as its continuation, if there is room for it.
+func (c *Canvas) SetCell(x, y uint, cell Cell) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if x >= c.w || y >= c.h {
+		return errors.New("out of bounds")
+	}
+	index := y*c.w + x
+	cr := ColorRune{fg: cell.Fg, bg: cell.Bg, r: cell.R, attr: cell.Attr}
+	switch {
+	case cell.Continuation:
+		cr.cw = 1
+	case cell.Wide && x+1 < c.w:
+		cr.cw = 2
+		c.chars[index+1] = ColorRune{fg: cell.Fg, bg: cell.Bg, cw: 1, attr: cell.Attr}
+	}
+	c.chars[index] = cr
+	return nil
+}
+
+// MarkDrawn marks the cell at (x, y) as drawn, so that Draw leaves it
+// untouched on the terminal even if it changed, until the cell is written
+// to again or the canvas is redrawn with Redraw. This lets another renderer
+// own a part of the terminal, for instance for an image. Coordinates that
+// are out of bounds are ignored.
+func (c *Canvas) MarkDrawn(x, y uint) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if x >= c.w || y >= c.h {
+		return
+	}
+	i := y*c.w + x
+	c.chars[i].drawn = true
+	if len(c.oldchars) == len(c.chars) {
+		// The other renderer may change what the terminal shows here
+		c.oldchars[i] = unknownCell
+	}
+}
+
+// Row returns a copy of the cells in row y, or nil if y is out of bounds
+func (c *Canvas) Row(y uint) []ColorRune {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	if y >= c.h {
+		return nil
+	}
+	row := make([]ColorRune, c.w)
+	copy(row, c.chars[y*c.w:(y+1)*c.w])
+	return row
+}
+
+// Column returns a copy of the cells in column x, or nil if x is out of bounds
+func (c *Canvas) Column(x uint) []ColorRune {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	if x >= c.w {
+		return nil
+	}
+	col := make([]ColorRune, c.h)
+	for y := range c.h {
+		col[y] = c.chars[y*c.w+x]
+	}
+	return col
+}
+
+// Find returns the position of the first cell containing r, scanning row
+// by row from the top left
+func (c *Canvas) Find(r rune) (x, y uint, found bool) {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	for i, cr := range c.chars {
+		if cr.cw != 1 && cr.r == r {
+			return uint(i) % c.w, uint(i) / c.w, true
+		}
+	}
+	return 0, 0, false
+}
+
+// FindString returns the position of the first occurrence of s within a
+// row, scanning row by row from the top left. Continuation cells of wide
+// runes are skipped, and empty cells match spaces.
+func (c *Canvas) FindString(s string) (x, y uint, found bool) {
+	want := []rune(s)
+	if len(want) == 0 {
+		return 0, 0, true
+	}
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	var (
+		xs    []uint
+		runes []rune
+	)
+	for y := range c.h {
+		xs, runes = xs[:0], runes[:0]
+		for x := range c.w {
+			cr := c.chars[y*c.w+x]
+			if cr.cw == 1 {
+				continue
+			}
+			r := cr.r
+			if r == 0 {
+				r = ' '
+			}
+			xs = append(xs, x)
+			runes = append(runes, r)
+		}
+		for i := 0; i+len(want) <= len(runes); i++ {
+			if slices.Equal(runes[i:i+len(want)], want) {
+				return xs[i], y, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
 // Plot sets the rune at (x, y) and marks the cell as undrawn
 func (c *Canvas) Plot(x, y uint, r rune) {
-	if x >= c.w || y >= c.h {
+	c.mut.Lock()
+	x, y, ok := c.padded(x, y)
+	if !ok || !c.inClip(x, y) {
+		c.mut.Unlock()
 		return
 	}
 	index := y*c.w + x
-	c.mut.Lock()
 	chars := (*c).chars
 	chars[index].r = r
 	chars[index].drawn = false
@@ -575,11 +1119,13 @@ func (c *Canvas) Plot(x, y uint, r rune) {
 
 // PlotColor sets the rune and foreground color at (x, y)
 func (c *Canvas) PlotColor(x, y uint, fg AttributeColor, r rune) {
-	if x >= c.w || y >= c.h {
+	c.mut.Lock()
+	x, y, ok := c.padded(x, y)
+	if !ok || !c.inClip(x, y) {
+		c.mut.Unlock()
 		return
 	}
 	index := y*c.w + x
-	c.mut.Lock()
 	chars := (*c).chars
 	chars[index].r = r
 	chars[index].fg = fg
@@ -592,89 +1138,178 @@ func (c *Canvas) Write(x, y uint, fg, bg AttributeColor, s string) {
 	c.WriteString(x, y, fg, bg, s)
 }
 
-// WriteString will write a string to the canvas
+// WriteString will write a string to the canvas. Wide runes take up two
+// cells, and a wide rune that does not fit at the end of a row is replaced
+// with a space.
 func (c *Canvas) WriteString(x, y uint, fg, bg AttributeColor, s string) {
-	if x >= c.w || y >= c.h {
-		return
-	}
+	c.writeString(x, y, fg, bg, 0, s)
+}
+
+// WriteStringStyled is like WriteString, but the cells also get the text
+// attributes in attr, like AttrBold or AttrUnderline
+func (c *Canvas) WriteStringStyled(x, y uint, fg, bg AttributeColor, attr TextAttr, s string) {
+	c.writeString(x, y, fg, bg, attr, s)
+}
+
+// writeString is the shared implementation of WriteString and
+// WriteStringStyled
+func (c *Canvas) writeString(x, y uint, fg, bg AttributeColor, attr TextAttr, s string) {
 	bgb := bg.Background()
 	c.mut.Lock()
+	x, y, ok := c.padded(x, y)
+	if !ok {
+		c.mut.Unlock()
+		return
+	}
 	chars := c.chars
 	startpos := y*c.w + x
 	lchars := uint(len(chars))
+	if c.padTop > 0 || c.padRight > 0 || c.padBottom > 0 || c.padLeft > 0 {
+		// Clip at the right edge of the padded area instead of wrapping
+		lchars = y*c.w + c.w - c.padRight
+	}
+	if startpos < lchars && chars[startpos].cw == 1 && startpos > 0 && c.inClip(x, y) {
+		// Blank the wide rune whose continuation cell is overwritten
+		chars[startpos-1] = ColorRune{fg: chars[startpos-1].fg, bg: chars[startpos-1].bg, r: ' '}
+	}
 	counter := uint(0)
 	for _, r := range s {
 		i := startpos + counter
 		if i >= lchars {
 			break
 		}
-		chars[i].r = r
-		chars[i].fg = fg
-		chars[i].bg = bgb
-		chars[i].drawn = false
+		if runeWidth(r) == 2 {
+			if i+1 < lchars && (i+1)%c.w != 0 {
+				c.writeWideRuneNoLock(i%c.w, i/c.w, fg, bgb, attr, r)
+				counter += 2
+				continue
+			}
+			r = ' ' // only half of the wide rune would fit
+		}
+		if c.inClip(i%c.w, i/c.w) {
+			chars[i] = ColorRune{fg: fg, bg: bgb, r: r, attr: attr}
+		}
 		counter++
 	}
+	if i := startpos + counter; counter > 0 && i < uint(len(chars)) && chars[i].cw == 1 && c.inClip((i-1)%c.w, (i-1)/c.w) {
+		// Blank the continuation cell of a wide rune that was overwritten
+		chars[i] = ColorRune{fg: chars[i].fg, bg: chars[i].bg, r: ' '}
+	}
 	c.mut.Unlock()
 }
 
+// WriteVertical writes s downwards from (x, y), one rune per row, clipping
+// at the bottom. Wide runes take up two columns, and are skipped if they do
+// not fit at the right edge. Combining marks and control characters are
+// skipped.
+func (c *Canvas) WriteVertical(x, y uint, fg, bg AttributeColor, s string) {
+	bgb := bg.Background()
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	x, y, ok := c.padded(x, y)
+	if !ok {
+		return
+	}
+	maxX, maxY := c.w-umin(c.padRight, c.w), c.h-umin(c.padBottom, c.h)
+	for _, r := range s {
+		if y >= maxY {
+			break
+		}
+		switch runeWidth(r) {
+		case 0:
+			continue
+		case 2:
+			if x+1 < maxX {
+				c.WriteWideRuneBNoLock(x, y, fg, bgb, r)
+			}
+		default:
+			if c.inClip(x, y) {
+				c.chars[y*c.w+x] = ColorRune{fg: fg, bg: bgb, r: r}
+			}
+		}
+		y++
+	}
+}
+
 // WriteRune will write a colored rune to the canvas
 func (c *Canvas) WriteRune(x, y uint, fg, bg AttributeColor, r rune) {
-	if x >= c.w || y >= c.h {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	x, y, ok := c.padded(x, y)
+	if !ok || !c.inClip(x, y) {
 		return
 	}
 	index := y*c.w + x
-	c.mut.Lock()
-	defer c.mut.Unlock()
 	chars := (*c).chars
 	chars[index].r = r
 	chars[index].fg = fg
 	chars[index].bg = bg.Background()
+	chars[index].attr = 0
 	chars[index].drawn = false
 }
 
+// WriteRuneStyled is like WriteRune, but the cell also gets the text
+// attributes in attr, like AttrBold or AttrUnderline
+func (c *Canvas) WriteRuneStyled(x, y uint, fg, bg AttributeColor, attr TextAttr, r rune) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	x, y, ok := c.padded(x, y)
+	if !ok || !c.inClip(x, y) {
+		return
+	}
+	index := y*c.w + x
+	c.chars[index] = ColorRune{fg: fg, bg: bg.Background(), r: r, cw: c.chars[index].cw, attr: attr}
+}
+
 // WriteRuneB will write a colored rune to the canvas.
 // The x and y must be within range (x < c.w and y < c.h).
 func (c *Canvas) WriteRuneB(x, y uint, fg, bgb AttributeColor, r rune) {
-	index := y*c.w + x
 	c.mut.Lock()
 	defer c.mut.Unlock()
-	(*c).chars[index] = ColorRune{fg, bgb, r, false, 0}
+	c.WriteRuneBNoLock(x, y, fg, bgb, r)
 }
 
 // WriteRuneBNoLock will write a colored rune to the canvas.
 // The x and y must be within range (x < c.w and y < c.h).
 // The canvas mutex is not locked.
 func (c *Canvas) WriteRuneBNoLock(x, y uint, fg, bgb AttributeColor, r rune) {
-	(*c).chars[y*c.w+x] = ColorRune{fg, bgb, r, false, 0}
+	if c.inClip(x, y) {
+		(*c).chars[y*c.w+x] = ColorRune{fg, bgb, r, false, 0, 0}
+	}
 }
 
 // WriteWideRuneB writes a double-width (CJK) rune to the canvas.
 // The next cell (x+1) is marked as a continuation cell and skipped during drawing.
 // The x and y must be within range (x+1 < c.w and y < c.h).
 func (c *Canvas) WriteWideRuneB(x, y uint, fg, bgb AttributeColor, r rune) {
-	base := y*c.w + x
 	c.mut.Lock()
 	defer c.mut.Unlock()
-	(*c).chars[base] = ColorRune{fg, bgb, r, false, 2}
-	(*c).chars[base+1] = ColorRune{fg, bgb, 0, false, 1}
+	c.WriteWideRuneBNoLock(x, y, fg, bgb, r)
 }
 
 // WriteWideRuneBNoLock writes a double-width (CJK) rune to the canvas without locking.
 // The next cell (x+1) is marked as a continuation cell and skipped during drawing.
 // The x and y must be within range (x+1 < c.w and y < c.h).
+// Nothing is written unless both cells are within the clip rectangle.
 func (c *Canvas) WriteWideRuneBNoLock(x, y uint, fg, bgb AttributeColor, r rune) {
+	c.writeWideRuneNoLock(x, y, fg, bgb, 0, r)
+}
+
+// writeWideRuneNoLock is WriteWideRuneBNoLock with text attributes
+func (c *Canvas) writeWideRuneNoLock(x, y uint, fg, bgb AttributeColor, attr TextAttr, r rune) {
+	if !c.inClip(x, y) || !c.inClip(x+1, y) {
+		return
+	}
 	base := y*c.w + x
-	(*c).chars[base] = ColorRune{fg, bgb, r, false, 2}
-	(*c).chars[base+1] = ColorRune{fg, bgb, 0, false, 1}
+	(*c).chars[base] = ColorRune{fg, bgb, r, false, 2, attr}
+	(*c).chars[base+1] = ColorRune{fg, bgb, 0, false, 1, attr}
 }
 
 // WriteBackground sets the background color at (x, y)
 func (c *Canvas) WriteBackground(x, y uint, bg AttributeColor) {
-	index := y*c.w + x
 	c.mut.Lock()
 	defer c.mut.Unlock()
-	(*c).chars[index].bg = bg
-	(*c).chars[index].drawn = false
+	c.WriteBackgroundNoLock(x, y, bg)
 }
 
 // WriteBackgroundAddRuneIfEmpty sets the background color at (x, y) and writes r if the cell is empty
@@ -682,6 +1317,9 @@ func (c *Canvas) WriteBackgroundAddRuneIfEmpty(x, y uint, bg AttributeColor, r r
 	index := y*c.w + x
 	c.mut.Lock()
 	defer c.mut.Unlock()
+	if !c.inClip(x, y) {
+		return
+	}
 	(*c).chars[index].bg = bg
 	if (*c).chars[index].r == 0 {
 		(*c).chars[index].r = r
@@ -691,6 +1329,9 @@ func (c *Canvas) WriteBackgroundAddRuneIfEmpty(x, y uint, bg AttributeColor, r r
 
 // WriteBackgroundNoLock sets the background color at (x, y) without locking
 func (c *Canvas) WriteBackgroundNoLock(x, y uint, bg AttributeColor) {
+	if !c.inClip(x, y) {
+		return
+	}
 	index := y*c.w + x
 	(*c).chars[index].bg = bg
 	(*c).chars[index].drawn = false
@@ -708,19 +1349,33 @@ func (c *Canvas) Unlock() {
 
 // WriteRunesB fills count cells starting at (x, y) with the given colored rune
 func (c *Canvas) WriteRunesB(x, y uint, fg, bgb AttributeColor, r rune, count uint) {
+	c.mut.Lock()
+	c.WriteRunesBNoLock(x, y, fg, bgb, r, count)
+	c.mut.Unlock()
+}
+
+// WriteRunesBNoLock is like WriteRunesB, but without locking
+func (c *Canvas) WriteRunesBNoLock(x, y uint, fg, bgb AttributeColor, r rune, count uint) {
 	startIndex := y*c.w + x
 	afterLastIndex := startIndex + count
-	c.mut.Lock()
 	chars := (*c).chars
 	for i := startIndex; i < afterLastIndex; i++ {
-		chars[i] = ColorRune{fg, bgb, r, false, 0}
+		if c.inClip(i%c.w, i/c.w) {
+			chars[i] = ColorRune{fg, bgb, r, false, 0, 0}
+		}
 	}
-	c.mut.Unlock()
 }
 
-// Resize adjusts the canvas to the current terminal size, discarding old content
+// Resize adjusts the canvas to the current terminal size, discarding old content.
+// It is safe to call Resize from a signal handling goroutine while another
+// goroutine is drawing; the resize waits until the current frame is built.
 func (c *Canvas) Resize() {
 	w, h := MustTermSize()
+	c.resizeTo(w, h)
+}
+
+// resizeTo adjusts the canvas to the given size, discarding old content
+func (c *Canvas) resizeTo(w, h uint) {
 	c.mut.Lock()
 	defer c.mut.Unlock()
 	if (w != c.w) || (h != c.h) {
@@ -728,6 +1383,8 @@ func (c *Canvas) Resize() {
 		c.h = h
 		c.chars = make([]ColorRune, w*h)
 		c.oldchars = nil
+		c.dimmed = nil
+		c.generation++
 	}
 }
 
@@ -735,7 +1392,13 @@ func (c *Canvas) Resize() {
 // Returns nil if the size has not changed.
 func (c *Canvas) Resized() *Canvas {
 	w, h := MustTermSize()
-	if (w != c.w) || (h != c.h) {
+	return c.resizedTo(w, h)
+}
+
+// resizedTo returns a new w x h Canvas with the contents and settings of c,
+// or nil if c already has that size
+func (c *Canvas) resizedTo(w, h uint) *Canvas {
+	if (w != c.W()) || (h != c.H()) {
 		// The terminal was resized!
 		oldc := c
 
@@ -749,6 +1412,27 @@ func (c *Canvas) Resized() *Canvas {
 		c.mut.Lock()
 		defer c.mut.Unlock()
 		defer nc.mut.Unlock()
+		// Keep the settings, like Resize does
+		nc.cursorVisible = c.cursorVisible
+		nc.termCursorVisible = c.termCursorVisible
+		nc.lineWrap = c.lineWrap
+		nc.runewise = c.runewise
+		nc.padTop = c.padTop
+		nc.padRight = c.padRight
+		nc.padBottom = c.padBottom
+		nc.padLeft = c.padLeft
+		nc.cursorX = c.cursorX
+		nc.cursorY = c.cursorY
+		nc.termX = c.termX
+		nc.termY = c.termY
+		nc.showAt = c.showAt
+		nc.showAtX = c.showAtX
+		nc.showAtY = c.showAtY
+		nc.shownAt = c.shownAt
+		nc.out = c.out
+		nc.clip = c.clip
+		nc.clipStack = slices.Clone(c.clipStack)
+		nc.statusLine = c.statusLine
 		// Copy over old characters, marking them as not yet drawn
 		for y := uint(0); y < umin(oldc.h, h); y++ {
 			for x := uint(0); x < umin(oldc.w, w); x++ {
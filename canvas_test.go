@@ -0,0 +1,945 @@
+package vt
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// discardStdout redirects os.Stdout to the null device until the returned
+// function is called
+func discardStdout(tb testing.TB) func() {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		tb.Skip(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = devNull
+	return func() {
+		os.Stdout = stdout
+		devNull.Close()
+	}
+}
+
+// fillRandom fills the canvas with random runes and colors
+func fillRandom(c *Canvas, rng *rand.Rand) {
+	for i := range c.chars {
+		c.chars[i] = ColorRune{
+			fg: Black + AttributeColor(rng.IntN(8)),
+			bg: BackgroundBlack + AttributeColor(rng.IntN(8)),
+			r:  rune('a' + rng.IntN(26)),
+		}
+	}
+}
+
+func TestCanvasConcurrentResizeAndDraw(t *testing.T) {
+	defer discardStdout(t)()
+
+	c := NewCanvasWithSize(40, 10)
+	const rounds = 500
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if i%2 == 0 {
+				c.resizeTo(20, 5)
+			} else {
+				c.resizeTo(60, 15)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		defer close(done)
+		for i := range rounds {
+			c.WriteString(uint(i%30), uint(i%8), Red, Blue, "hello")
+			c.Plot(uint(i%60), uint(i%15), 'x')
+			c.Draw()
+		}
+	}()
+	wg.Wait()
+}
+
+func TestCanvasPaddingArea(t *testing.T) {
+	c := NewCanvasWithSize(6, 3)
+	c.SetPaddingArea(1, 1, 1, 1)
+	c.WriteString(0, 0, Default, Default, "abcdef")
+	c.Plot(0, 1, 'x') // bottom margin
+	if got, want := c.String(), "      \n abcd \n      \n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	// Text is clipped instead of wrapped when only the top is padded
+	c = NewCanvasWithSize(4, 3)
+	c.SetPaddingArea(1, 0, 0, 0)
+	c.WriteString(2, 0, Default, Default, "abcd")
+	if got, want := c.String(), "    \n  ab\n    \n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	c.ClearPaddingArea()
+	c.Plot(0, 0, 'z')
+	if r, _ := c.At(0, 0); r != 'z' {
+		t.Errorf("expected z at (0,0) after ClearPaddingArea, got %q", r)
+	}
+}
+
+func TestCanvasLineWrap(t *testing.T) {
+	c := NewCanvasWithSize(2, 2)
+	if c.LineWrap() {
+		t.Error("line wrap should be disabled by default")
+	}
+	var buf strings.Builder
+	c.SetOutput(&buf)
+	c.SetLineWrap(true)
+	if got, want := buf.String(), "\033[?7h"; !c.LineWrap() || got != want {
+		t.Errorf("got %q (enabled: %v), want %q", got, c.LineWrap(), want)
+	}
+	buf.Reset()
+	c.SetLineWrap(false)
+	if got, want := buf.String(), "\033[?7l"; c.LineWrap() || got != want {
+		t.Errorf("got %q (enabled: %v), want %q", got, c.LineWrap(), want)
+	}
+}
+
+func TestCanvasStringTrimmed(t *testing.T) {
+	c := NewCanvasWithSize(5, 5)
+	c.WriteString(1, 0, Default, DefaultBackground, "ab ")
+	c.WriteString(0, 2, Default, DefaultBackground, "c d")
+	c.WriteString(0, 3, Default, DefaultBackground, "     ")
+	if got, want := c.StringTrimmed(), " ab\n\nc d\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := NewCanvasWithSize(3, 2).StringTrimmed(); got != "" {
+		t.Errorf("got %q for a blank canvas, want an empty string", got)
+	}
+}
+
+func TestCanvasPulseRegion(t *testing.T) {
+	defer discardStdout(t)()
+	c := NewCanvasWithSize(4, 3)
+	c.FillBackground(BackgroundBlue)
+	c.WriteString(1, 1, Default, BackgroundGreen, "ab")
+	c.Draw()
+	before := c.Row(1)
+	c.PulseRegion(1, 1, 5, 5, BackgroundRed, Yellow, 3, 0)
+	if after := c.Row(1); !slices.Equal(after, before) {
+		t.Errorf("the original colors were not restored:\n%+v\n%+v", before, after)
+	}
+	if !slices.Equal(c.oldchars, c.chars) {
+		t.Error("expected the last Draw to leave the terminal in sync with the canvas")
+	}
+}
+
+func TestCanvasDrawReportsOutput(t *testing.T) {
+	defer discardStdout(t)()
+	c := NewCanvasWithSize(3, 2)
+	if !c.Draw() {
+		t.Error("expected the first Draw to write the frame")
+	}
+	if c.Draw() {
+		t.Error("expected nothing to be written when nothing changed")
+	}
+	c.Plot(1, 1, 'x')
+	if !c.HideCursorAndDraw() {
+		t.Error("expected the changed cell to be written")
+	}
+}
+
+func TestCanvasRowAndColumn(t *testing.T) {
+	c := NewCanvasWithSize(3, 2)
+	c.WriteString(0, 1, Red, BackgroundBlue, "abc")
+	row := c.Row(1)
+	if len(row) != 3 || row[2].Rune() != 'c' || row[2].Fg() != Red || row[2].Bg() != BackgroundBlue {
+		t.Errorf("unexpected row: %+v", row)
+	}
+	row[0].r = 'z' // the row is a copy
+	if r, _ := c.At(0, 1); r != 'a' {
+		t.Errorf("modifying the row changed the canvas: got %q", r)
+	}
+	col := c.Column(1)
+	if len(col) != 2 || col[0].Rune() != 0 || col[1].Rune() != 'b' {
+		t.Errorf("unexpected column: %+v", col)
+	}
+	if c.Row(2) != nil || c.Column(3) != nil {
+		t.Error("expected nil for out of bounds rows and columns")
+	}
+}
+
+// benchmarkDraw draws a w x h canvas filled with random content b.N times.
+// Every frame is a full redraw, unless dirty is between 0 and 1, in which
+// case only that fraction of the cells is changed between frames.
+func benchmarkDraw(b *testing.B, w, h uint, runewise bool, dirty float64) {
+	defer discardStdout(b)()
+	rng := rand.New(rand.NewPCG(1, 2))
+	c := NewCanvasWithSize(w, h)
+	c.SetRunewise(runewise)
+	fillRandom(c, rng)
+	cells := int(w * h)
+	changes := int(float64(cells) * dirty)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if changes > 0 {
+			for range changes {
+				c.chars[rng.IntN(cells)].r = rune('a' + rng.IntN(26))
+			}
+		} else {
+			c.oldchars = nil
+		}
+		c.Draw()
+	}
+	b.ReportMetric(float64(cells)*float64(b.N)/b.Elapsed().Seconds(), "cells/s")
+}
+
+func BenchmarkCanvasDraw20x5(b *testing.B)              { benchmarkDraw(b, 20, 5, false, 0) }
+func BenchmarkCanvasDraw80x24(b *testing.B)             { benchmarkDraw(b, 80, 24, false, 0) }
+func BenchmarkCanvasDraw220x60(b *testing.B)            { benchmarkDraw(b, 220, 60, false, 0) }
+func BenchmarkCanvasDrawRunewise80x24(b *testing.B)     { benchmarkDraw(b, 80, 24, true, 0) }
+func BenchmarkCanvasDrawPartialDirty80x24(b *testing.B) { benchmarkDraw(b, 80, 24, false, 0.01) }
+
+func TestCanvasDimRegion(t *testing.T) {
+	c := NewCanvasWithSize(4, 2)
+	c.WriteString(0, 0, Red, BackgroundBlue, "ab")
+	c.WriteString(2, 0, TrueColor(200, 100, 50), TrueBackground(20, 40, 60), "cd")
+	before := slices.Clone(c.chars)
+
+	c.DimAll()
+	c.DimRegion(0, 0, 1, 1) // dimming twice should not lose the original
+	if cr := c.chars[0]; cr.fg != Red.Combine(Dim) || cr.bg != BackgroundBlue {
+		t.Errorf("unexpected 16-color dimming: %+v", cr)
+	}
+	if r, g, b, _ := ToRGB(c.chars[2].fg); r != 100 || g != 50 || b != 25 {
+		t.Errorf("unexpected true color dimming: %d %d %d", r, g, b)
+	}
+
+	c.WriteString(1, 1, White, BackgroundBlack, "ok") // a dialog
+	c.UndimAll()
+	for i := range before {
+		if c.chars[i].r != before[i].r || c.chars[i].fg != before[i].fg || c.chars[i].bg != before[i].bg {
+			t.Errorf("cell %d was not restored: got %+v, want %+v", i, c.chars[i], before[i])
+		}
+	}
+}
+
+func TestCanvasFind(t *testing.T) {
+	c := NewCanvasWithSize(8, 2)
+	c.WriteString(0, 0, Default, DefaultBackground, "ab")
+	c.DrawSprite(1, 1, []string{"日本 x"}, Default, DefaultBackground)
+	if x, y, found := c.Find('x'); !found || x != 6 || y != 1 {
+		t.Errorf("Find: got (%d, %d, %v), want (6, 1, true)", x, y, found)
+	}
+	if _, _, found := c.Find('z'); found {
+		t.Error("Find: found a rune that is not on the canvas")
+	}
+	if x, y, found := c.FindString("本 x"); !found || x != 3 || y != 1 {
+		t.Errorf("FindString: got (%d, %d, %v), want (3, 1, true)", x, y, found)
+	}
+	if x, y, found := c.FindString("b  "); !found || x != 1 || y != 0 {
+		t.Errorf("FindString: got (%d, %d, %v), want (1, 0, true)", x, y, found)
+	}
+	if _, _, found := c.FindString("ba"); found {
+		t.Error("FindString: found a string that is not on the canvas")
+	}
+}
+
+func TestCanvasTerminalCursor(t *testing.T) {
+	defer discardStdout(t)()
+	c := NewCanvasWithSize(4, 3)
+	c.Draw()
+	if x, y := c.TerminalCursor(); x != 3 || y != 2 {
+		t.Errorf("after a full frame: got (%d, %d), want (3, 2)", x, y)
+	}
+	c.Plot(1, 0, 'x')
+	c.Draw()
+	if x, y := c.TerminalCursor(); x != 3 || y != 0 {
+		t.Errorf("after redrawing the first line: got (%d, %d), want (3, 0)", x, y)
+	}
+	c.SetLogicalCursor(1, 2)
+	c.RestoreCursorToLogical()
+	if x, y := c.TerminalCursor(); x != 1 || y != 2 {
+		t.Errorf("after RestoreCursorToLogical: got (%d, %d), want (1, 2)", x, y)
+	}
+}
+
+func TestCanvasShowCursorAt(t *testing.T) {
+	defer discardStdout(t)()
+	c := NewCanvasWithSize(4, 3)
+	c.Draw()
+	c.ShowCursorAt(1, 1)
+	c.Draw() // nothing changed, but the cursor should still be shown
+	if !c.termCursorVisible {
+		t.Error("expected the cursor to be shown after the next Draw")
+	}
+	if x, y := c.TerminalCursor(); x != 1 || y != 1 {
+		t.Errorf("got the cursor at (%d, %d), want (1, 1)", x, y)
+	}
+	c.Draw()
+	if c.termCursorVisible {
+		t.Error("expected the cursor to be hidden by the following Draw")
+	}
+	c.ShowCursorAt(2, 0)
+	c.Plot(0, 0, 'x')
+	c.Draw()
+	if x, y := c.TerminalCursor(); !c.termCursorVisible || x != 2 || y != 0 {
+		t.Errorf("got the cursor at (%d, %d), visible: %v, want (2, 0) and visible", x, y, c.termCursorVisible)
+	}
+	c.Plot(1, 0, 'y')
+	c.Draw()
+	if c.termCursorVisible {
+		t.Error("expected the cursor to be hidden by the following Draw")
+	}
+}
+
+func TestCanvasWriteVertical(t *testing.T) {
+	c := NewCanvasWithSize(3, 3)
+	c.WriteVertical(0, 0, Red, Blue, "a日bc")
+	for y, want := range []rune{'a', '日', 'b'} {
+		if r, _ := c.At(0, uint(y)); r != want {
+			t.Errorf("row %d: got %q, want %q", y, r, want)
+		}
+	}
+	if c.chars[c.W()].cw != 2 || c.chars[c.W()+1].cw != 1 {
+		t.Error("expected the wide rune to take up two cells")
+	}
+	c.WriteVertical(2, 1, Red, Blue, "日x")
+	if r, _ := c.At(2, 1); r != 0 {
+		t.Errorf("expected a wide rune at the right edge to be skipped, got %q", r)
+	}
+	if r, _ := c.At(2, 2); r != 'x' {
+		t.Errorf("got %q, want 'x'", r)
+	}
+}
+
+func TestCanvasSetOutput(t *testing.T) {
+	var buf strings.Builder
+	c := NewCanvasWithSize(3, 2)
+	c.SetOutput(&buf)
+	c.Plot(0, 0, 'x')
+	c.Draw()
+	if got := buf.String(); !strings.Contains(got, "x") || !strings.Contains(got, beginSyncUpdate) {
+		t.Errorf("expected the frame to be written to the writer, got %q", got)
+	}
+	buf.Reset()
+	c.HideCursor()
+	c.HideCursor()
+	if got := buf.String(); got != "" {
+		t.Errorf("expected no cursor escape after a Draw, which hides the cursor, got %q", got)
+	}
+
+	// Swapping the writer redraws everything, and assumes a visible cursor
+	var other strings.Builder
+	c.SetOutput(&other)
+	c.HideCursor()
+	if got := other.String(); got != hideCursor {
+		t.Errorf("got %q, want a single hide cursor escape", got)
+	}
+	if !c.Draw() || !strings.Contains(other.String(), "x") {
+		t.Error("expected the full frame to be written to the new writer")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("nothing more should be written to the old writer, got %q", buf.String())
+	}
+}
+
+func TestCanvasCellAt(t *testing.T) {
+	c := NewCanvasWithSize(4, 1)
+	c.WriteRune(0, 0, Red, Blue, 'a')
+	c.WriteWideRuneB(1, 0, Green, BackgroundBlack, '日')
+	var cells []Cell
+	for x := range uint(4) {
+		cell, err := c.CellAt(x, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cells = append(cells, cell)
+	}
+	if cells[0] != (Cell{R: 'a', Fg: Red, Bg: BackgroundBlue}) {
+		t.Errorf("unexpected cell: %+v", cells[0])
+	}
+	if !cells[1].Wide || cells[1].R != '日' || !cells[2].Continuation {
+		t.Errorf("expected a wide cell followed by a continuation cell: %+v %+v", cells[1], cells[2])
+	}
+	if _, err := c.CellAt(4, 0); err == nil {
+		t.Error("expected an error for a cell that is out of bounds")
+	}
+
+	// Round-trip the cells onto another canvas
+	d := NewCanvasWithSize(4, 1)
+	for x, cell := range cells {
+		if err := d.SetCell(uint(x), 0, cell); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !slices.Equal(d.chars, c.chars) {
+		t.Errorf("the cells did not round-trip:\n%+v\n%+v", c.chars, d.chars)
+	}
+}
+
+func TestCanvasDraw256ColorsUnchanged(t *testing.T) {
+	var buf strings.Builder
+	c := NewCanvasWithSize(3, 2)
+	c.SetOutput(&buf)
+	c.WriteString(0, 0, Color256(208), Background256(17), "abc")
+	if !c.Draw() || !strings.Contains(buf.String(), "\033[38;5;208m") && !EnvNoColor {
+		t.Fatalf("expected the 256-color cells to be written, got %q", buf.String())
+	}
+	if c.Draw() {
+		t.Error("unchanged 256-color cells should not be written again")
+	}
+}
+
+func TestCanvasDrawRegion(t *testing.T) {
+	var buf strings.Builder
+	c := NewCanvasWithSize(6, 3)
+	c.SetOutput(&buf)
+	c.Draw()
+	c.WriteString(0, 0, Default, Default, "ab")
+	c.WriteString(4, 1, Default, Default, "cd")
+	buf.Reset()
+	if !c.DrawRegion(3, 0, 3, 3) {
+		t.Fatal("expected the changed cells in the region to be written")
+	}
+	got := buf.String()
+	if !strings.Contains(got, "\033[2;5H") || !strings.Contains(got, "cd") || strings.Contains(got, "ab") {
+		t.Errorf("expected only the changed cells within the region, got %q", got)
+	}
+	buf.Reset()
+	if c.DrawRegion(3, 0, 3, 3) {
+		t.Errorf("expected nothing to be written for an unchanged region, got %q", buf.String())
+	}
+	// The cells outside of the region are still drawn by the next Draw
+	if !c.Draw() || !strings.Contains(buf.String(), "ab") || strings.Contains(buf.String(), "cd") {
+		t.Errorf("expected Draw to write only the remaining changes, got %q", buf.String())
+	}
+	buf.Reset()
+	c.RedrawRegion(4, 1, 2, 1)
+	if got := buf.String(); !strings.Contains(got, "cd") {
+		t.Errorf("expected RedrawRegion to write the cells again, got %q", got)
+	}
+	if c.DrawRegion(6, 0, 2, 2) {
+		t.Error("expected nothing to be written for a region outside of the canvas")
+	}
+}
+
+func TestCanvasEachChangedCell(t *testing.T) {
+	c := NewCanvasWithSize(3, 2)
+	count := 0
+	c.EachChangedCell(func(x, y uint, cell ColorRune) { count++ })
+	if count != 6 {
+		t.Errorf("expected all 6 cells the first time, got %d", count)
+	}
+	c.WriteRune(2, 1, Red, Default, 'x')
+	c.WriteWideRuneB(0, 0, Red, Default, '日')
+	var got []string
+	c.EachChangedCell(func(x, y uint, cell ColorRune) {
+		got = append(got, fmt.Sprintf("%d,%d:%c", x, y, cell.Rune()))
+	})
+	if want := []string{"0,0:日", "2,1:x"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	count = 0
+	c.EachChangedCell(func(x, y uint, cell ColorRune) { count++ })
+	if count != 0 {
+		t.Errorf("expected no changed cells, got %d", count)
+	}
+}
+
+func TestCanvasMarkDrawn(t *testing.T) {
+	var buf strings.Builder
+	c := NewCanvasWithSize(4, 2)
+	c.SetOutput(&buf)
+	c.WriteString(0, 0, Default, Default, "abcd")
+	c.MarkDrawn(1, 0)
+	c.Draw()
+	if got := buf.String(); strings.Contains(got, "b") || !strings.Contains(got, "a\033[1;3Hcd") {
+		t.Errorf("expected the marked cell to be skipped, got %q", got)
+	}
+	buf.Reset()
+	c.WriteString(0, 0, Default, Default, "xyz")
+	c.MarkDrawn(2, 0)
+	c.Draw()
+	if got := buf.String(); !strings.Contains(got, "xy") || strings.Contains(got, "z") {
+		t.Errorf("expected only the unmarked cells to be written, got %q", got)
+	}
+	buf.Reset()
+	c.MarkDrawn(9, 9) // ignored
+	if c.Draw() {
+		t.Errorf("expected nothing to be written, got %q", buf.String())
+	}
+}
+
+func TestCanvasMarkDrawnRevert(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		draw func(c *Canvas) bool
+	}{
+		{"Draw", (*Canvas).Draw},
+		{"DrawRegion", func(c *Canvas) bool { return c.DrawRegion(0, 0, 4, 2) }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf strings.Builder
+			c := NewCanvasWithSize(4, 2)
+			c.SetOutput(&buf)
+			c.WriteString(0, 0, Default, Default, "ab")
+			c.Draw()
+			// Another renderer takes over the cell, which is then given
+			// back its earlier content
+			c.MarkDrawn(1, 0)
+			tc.draw(c)
+			c.WriteString(1, 0, Default, Default, "x")
+			c.WriteString(1, 0, Default, Default, "b")
+			buf.Reset()
+			if !tc.draw(c) || !strings.Contains(buf.String(), "b") {
+				t.Errorf("expected the cell to be repainted, got %q", buf.String())
+			}
+		})
+	}
+}
+
+func TestCanvasDrawPositionsEachRow(t *testing.T) {
+	// Every row that is written starts with absolute cursor positioning,
+	// so that the output resynchronizes if a byte is lost on the way
+	var buf strings.Builder
+	c := NewCanvasWithSize(4, 3)
+	c.SetOutput(&buf)
+	c.WriteString(0, 0, Default, Default, "abcd")
+	c.WriteString(0, 1, Default, Default, "efgh")
+	c.Draw()
+	for y := 1; y <= 2; y++ {
+		if pos := fmt.Sprintf("\033[%d;1H", y); !strings.Contains(buf.String(), pos) {
+			t.Errorf("expected row %d to be positioned with %q, got %q", y, pos, buf.String())
+		}
+	}
+	buf.Reset()
+	c.WriteString(0, 1, Default, Default, "x")
+	c.Draw()
+	if got := buf.String(); !strings.Contains(got, "\033[2;1H") || strings.Contains(got, "\033[1;1H") {
+		t.Errorf("expected only the changed row to be positioned and written, got %q", got)
+	}
+}
+
+func TestCanvasWriteStringWide(t *testing.T) {
+	c := NewCanvasWithSize(5, 2)
+	c.WriteString(0, 0, Red, Blue, "a日本")
+	if c.chars[1].r != '日' || c.chars[1].cw != 2 || c.chars[2].cw != 1 || c.chars[3].r != '本' || c.chars[4].cw != 1 {
+		t.Errorf("expected wide runes to take up two cells each: %+v", c.chars[:5])
+	}
+
+	// A wide rune that does not fit at the end of a row is replaced
+	c.WriteString(3, 1, Red, Blue, "x日")
+	if c.chars[9].r != ' ' || c.chars[9].cw != 0 {
+		t.Errorf("expected a space at the end of the row, got %+v", c.chars[9])
+	}
+
+	// Overwriting half of a wide rune blanks the other half
+	c.WriteString(2, 0, Red, Blue, "z")
+	if c.chars[1].r != ' ' || c.chars[1].cw != 0 || c.chars[2].r != 'z' {
+		t.Errorf("expected the wide rune to be blanked: %+v", c.chars[:5])
+	}
+	c.WriteString(3, 0, Red, Blue, "q")
+	if c.chars[4].r != ' ' || c.chars[4].cw != 0 {
+		t.Errorf("expected the orphaned continuation cell to be blanked: %+v", c.chars[:5])
+	}
+}
+
+func TestCanvasRemapColor(t *testing.T) {
+	c := NewCanvasWithSize(3, 1)
+	c.WriteRune(0, 0, Blue, White, 'a')
+	c.WriteRune(1, 0, White, Blue, 'b')
+	c.WriteRune(2, 0, Red, Black, 'c')
+	c.RemapColor(Blue, TrueColor(1, 2, 3))
+	want := []ColorRune{
+		{fg: TrueColor(1, 2, 3), bg: White.Background(), r: 'a'},
+		{fg: White, bg: TrueBackground(1, 2, 3), r: 'b'},
+		{fg: Red, bg: Black.Background(), r: 'c'},
+	}
+	if !slices.Equal(c.chars, want) {
+		t.Errorf("got %+v, want %+v", c.chars, want)
+	}
+}
+
+func TestCanvasClip(t *testing.T) {
+	c := NewCanvasWithSize(6, 3)
+	c.SetClip(1, 0, 3, 2)
+	c.WriteString(0, 0, Default, DefaultBackground, "abcdef")
+	c.WriteRuneB(4, 1, Default, DefaultBackground, 'x')
+	c.WriteRunesB(0, 1, Default, DefaultBackground, '-', 6)
+	c.Plot(0, 2, 'y')
+	if got, want := c.String(), " bcd  \n ---  \n      \n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	// Nested clip rectangles are intersected, and popped in order
+	c.Clear()
+	c.PushClip(2, 0, 4, 3)
+	c.FillRect(0, 0, 6, 3, Default, DefaultBackground, '#')
+	c.PopClip()
+	c.WriteString(0, 0, Default, DefaultBackground, "abcdef")
+	c.ClearClip()
+	c.WriteString(0, 2, Default, DefaultBackground, "abcdef")
+	if got, want := c.String(), " bcd  \n  ##  \nabcdef\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCanvasSaveSnapshotAndRestore(t *testing.T) {
+	c := NewCanvasWithSize(4, 2)
+	c.WriteString(0, 0, Red, Blue, "ab日")
+	c.SetLineWrap(true)
+	snap := c.SaveSnapshot()
+	want := c.String()
+
+	c.Clear()
+	c.SetLineWrap(false)
+	if err := c.Restore(snap); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.String(); got != want || !c.LineWrap() {
+		t.Errorf("got %q (line wrap %v), want %q with line wrap", got, c.LineWrap(), want)
+	}
+
+	// A snapshot survives a round trip through Marshal
+	decoded, err := UnmarshalCanvasSnapshot(snap.Marshal())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Clear()
+	if err := c.Restore(decoded); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if cr := c.chars[2]; cr.fg != Red || cr.bg != Blue.Background() || cr.cw != 2 || cr.drawn {
+		t.Errorf("got %+v after unmarshaling", cr)
+	}
+
+	if err := NewCanvasWithSize(3, 2).Restore(snap); err == nil {
+		t.Error("expected an error when restoring a snapshot of another size")
+	}
+	if _, err := UnmarshalCanvasSnapshot(snap.Marshal()[:20]); err == nil {
+		t.Error("expected an error for truncated data")
+	}
+}
+
+func TestCanvasSubCanvas(t *testing.T) {
+	c := NewCanvasWithSize(6, 3)
+	v := c.SubCanvas(2, 1, 3, 5)
+	if w, h := v.Size(); w != 3 || h != 2 {
+		t.Errorf("got %dx%d, want the view to be clipped to 3x2", w, h)
+	}
+	v.WriteString(1, 0, Default, DefaultBackground, "abcdef")
+	v.WriteRune(0, 1, Default, DefaultBackground, 'x')
+	v.WriteRune(3, 1, Default, DefaultBackground, 'y') // outside of the view
+	if got, want := c.String(), "      \n   ab \n  x   \n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if r, err := v.At(1, 0); err != nil || r != 'a' {
+		t.Errorf("got %q (%v), want 'a'", r, err)
+	}
+	// A view of a view is offset and clipped by both
+	inner := v.SubCanvas(1, 1, 4, 4)
+	if w, h := inner.Size(); w != 2 || h != 1 {
+		t.Errorf("got %dx%d, want the inner view to be clipped to 2x1", w, h)
+	}
+	inner.WriteString(0, 0, Default, DefaultBackground, "pqr")
+	if got, want := c.String(), "      \n   ab \n  xpq \n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	// A resize to another size leaves the view empty
+	c.resizeTo(8, 4)
+	if w, h := v.Size(); w != 0 || h != 0 {
+		t.Errorf("got %dx%d, want 0x0 after a resize", w, h)
+	}
+	v.WriteRune(0, 0, Default, DefaultBackground, 'z')
+	if _, _, found := c.Find('z'); found {
+		t.Error("expected writes to a stale view to be discarded")
+	}
+}
+
+func TestCanvasCopyKeepsStatusLineAndClip(t *testing.T) {
+	c := NewCanvasWithSize(6, 3)
+	c.ReserveStatusLine()
+	c.SetClip(0, 0, 3, 3)
+	cc := c.Copy()
+	var buf strings.Builder
+	cc.SetOutput(&buf)
+	cc.WriteString(0, 0, Default, DefaultBackground, "abcdef")
+	cc.WriteString(0, 2, Default, DefaultBackground, "hidden")
+	if got, want := cc.String(), "abc   \n      \nhid   \n"; got != want {
+		t.Errorf("expected the clip to be copied, got %q, want %q", got, want)
+	}
+	cc.Draw()
+	if out := buf.String(); strings.Contains(out, "hid") {
+		t.Errorf("expected Draw on the copy to leave the status line alone, got %q", out)
+	}
+
+	// The dimmed cells are copied, and can be undimmed on the copy alone
+	c.WriteString(0, 1, Red, DefaultBackground, "xy")
+	c.DimRegion(0, 1, 2, 1)
+	cc = c.Copy()
+	if got, want := cc.Row(1)[0], c.Row(1)[0]; got != want {
+		t.Errorf("expected the dimmed cell to be copied, got %+v, want %+v", got, want)
+	}
+	cc.UndimAll()
+	if cr := cc.Row(1)[0]; cr.fg != Red {
+		t.Errorf("expected UndimAll on the copy to restore the cell, got %+v", cr)
+	}
+	if cr := c.Row(1)[0]; cr.fg == Red {
+		t.Errorf("expected the original to stay dimmed, got %+v", cr)
+	}
+}
+
+func TestCanvasStatusLine(t *testing.T) {
+	c := NewCanvasWithSize(6, 3)
+	var buf strings.Builder
+	c.SetOutput(&buf)
+	c.ReserveStatusLine()
+	c.WriteString(0, 2, Default, DefaultBackground, "hidden")
+	c.WriteString(0, 0, Default, DefaultBackground, "top")
+	c.Draw()
+	if out := buf.String(); strings.Contains(out, "hidden") || strings.Contains(out, "\033[3;1H") {
+		t.Errorf("expected Draw to leave the status line alone, got %q", out)
+	}
+
+	buf.Reset()
+	c.SetStatus(Default, DefaultBackground, "ready")
+	out := buf.String()
+	if !strings.Contains(out, "\033[3;1H") || !strings.Contains(out, "ready") {
+		t.Errorf("expected the status line to be written, got %q", out)
+	}
+	if strings.Contains(out, "top") {
+		t.Errorf("expected only the status line to be written, got %q", out)
+	}
+	if got := c.Row(2); got[0].r != 'r' || got[5].r != ' ' {
+		t.Errorf("got %+v", got)
+	}
+
+	// Changes to the rest of the canvas are still drawn
+	buf.Reset()
+	c.WriteString(0, 1, Default, DefaultBackground, "mid")
+	if !c.Draw() || !strings.Contains(buf.String(), "mid") {
+		t.Errorf("got %q", buf.String())
+	}
+}
+
+func TestCanvasWriteStringStyled(t *testing.T) {
+	if EnvNoColor {
+		t.Skip("NO_COLOR is set")
+	}
+	c := NewCanvasWithSize(8, 2)
+	var buf strings.Builder
+	c.SetOutput(&buf)
+	c.WriteString(0, 0, Default, DefaultBackground, "ab")
+	c.Draw()
+
+	// Changing only the attributes of a cell is drawn
+	buf.Reset()
+	c.WriteStringStyled(0, 0, Default, DefaultBackground, AttrBold|AttrUnderline, "a")
+	c.WriteRuneStyled(1, 0, Default, DefaultBackground, AttrReverse, 'b')
+	if !c.Draw() {
+		t.Fatal("expected the styled cells to be drawn")
+	}
+	out := buf.String()
+	if !strings.Contains(out, "\033[1;4ma") || !strings.Contains(out, "\033[7mb") {
+		t.Errorf("expected the attributes to be turned on, got %q", out)
+	}
+	if !strings.Contains(out, "\033[22;23;24m") {
+		t.Errorf("expected the attributes to be reset between cells, got %q", out)
+	}
+	if cell, _ := c.CellAt(0, 0); cell.Attr != AttrBold|AttrUnderline {
+		t.Errorf("got attributes %v", cell.Attr)
+	}
+
+	// A plain write removes the attributes
+	c.WriteString(0, 0, Default, DefaultBackground, "a")
+	if cr := c.Row(0)[0]; cr.Attr() != 0 {
+		t.Errorf("got attributes %v after a plain write", cr.Attr())
+	}
+	if got := (AttrDim | AttrStrikethrough).String(); got != "\033[2;9m" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestAutoResizeCanvas(t *testing.T) {
+	sigChan := make(chan os.Signal, 1)
+	var (
+		mut        sync.Mutex
+		w, h       uint = 6, 3
+		calls      int
+		gotW, gotH uint
+	)
+	termSize := func() (uint, uint) {
+		mut.Lock()
+		defer mut.Unlock()
+		return w, h
+	}
+	resized := make(chan *Canvas, 4)
+	c := NewCanvasWithSize(4, 2)
+	c.WriteString(0, 0, Default, DefaultBackground, "ab")
+	a := startAutoResize(c, sigChan, termSize, func(nc *Canvas, oldW, oldH, newW, newH uint) {
+		mut.Lock()
+		calls++
+		gotW, gotH = oldW, oldH
+		mut.Unlock()
+		resized <- nc
+	})
+	defer a.Close()
+
+	// A burst of signals results in a single resize
+	for range 3 {
+		sigChan <- os.Interrupt
+	}
+	nc := <-resized
+	if w, h := nc.Size(); w != 6 || h != 3 {
+		t.Errorf("got a %dx%d canvas, want 6x3", w, h)
+	}
+	if r, _ := nc.At(1, 0); r != 'b' {
+		t.Errorf("expected the contents to be kept, got %q", r)
+	}
+	if a.Canvas() != nc {
+		t.Error("expected Canvas to return the resized canvas")
+	}
+	a.Close()
+	mut.Lock()
+	defer mut.Unlock()
+	if calls != 1 || gotW != 4 || gotH != 2 {
+		t.Errorf("got %d calls with the old size %dx%d, want 1 call with 4x2", calls, gotW, gotH)
+	}
+}
+
+func TestAutoResizeCanvasKeepsSettings(t *testing.T) {
+	sigChan := make(chan os.Signal, 1)
+	resized := make(chan *Canvas, 1)
+	c := NewCanvasWithSize(4, 3)
+	var buf strings.Builder
+	c.SetOutput(&buf)
+	c.SetLineWrap(true)
+	c.ShowCursor()
+	c.SetLogicalCursor(2, 1)
+	c.ReserveStatusLine()
+	c.SetPaddingArea(0, 0, 0, 1)
+	c.SetClip(0, 0, 2, 2)
+	c.PushClip(0, 0, 1, 1)
+	a := startAutoResize(c, sigChan, func() (uint, uint) { return 6, 3 }, func(nc *Canvas, oldW, oldH, newW, newH uint) {
+		resized <- nc
+	})
+	defer a.Close()
+	sigChan <- os.Interrupt
+	nc := <-resized
+
+	if !nc.LineWrap() {
+		t.Error("expected line wrapping to be kept")
+	}
+	if !nc.cursorVisible {
+		t.Error("expected the cursor to stay visible")
+	}
+	if x, y := nc.LogicalCursor(); x != 2 || y != 1 {
+		t.Errorf("got the logical cursor at (%d, %d), want (2, 1)", x, y)
+	}
+	nc.WriteString(0, 0, Default, DefaultBackground, "abc")
+	nc.PopClip()
+	nc.WriteString(0, 1, Default, DefaultBackground, "abcdef")
+	nc.ClearClip()
+	nc.WriteString(0, 2, Default, DefaultBackground, "st")
+	if got, want := nc.String(), " a    \n ab   \n st   \n"; got != want {
+		t.Errorf("expected the padding and clip to be kept, got %q, want %q", got, want)
+	}
+	buf.Reset()
+	nc.Draw()
+	if strings.Contains(buf.String(), "st") {
+		t.Error("expected the status line to be kept, and not drawn by Draw")
+	}
+	if buf.Len() == 0 {
+		t.Error("expected the output to be kept")
+	}
+}
+
+func TestCanvasExportANSI(t *testing.T) {
+	if EnvNoColor {
+		t.Skip("NO_COLOR is set")
+	}
+	c := NewCanvasWithSize(4, 2)
+	c.WriteString(0, 0, Red, Blue, "ab")
+	c.WriteString(2, 0, Green, Blue, "cd")
+	c.WriteString(0, 1, Yellow, Black, "efgh")
+	out := c.ExportANSI()
+	if !strings.HasSuffix(out, NoColor+"\n") {
+		t.Errorf("expected a final reset, got %q", out)
+	}
+	if n := strings.Count(out, Red.Combine(Blue.Background()).String()); n != 1 {
+		t.Errorf("expected the colors of the first run to be emitted once, got %d times in %q", n, out)
+	}
+
+	// Parse the output again, and compare with the cells
+	var parsed []CharAttribute
+	n := NewTextOutput(true, true).ExtractToSlice(out, &parsed)
+	var i uint
+	for y := range c.H() {
+		for _, cr := range c.Row(y) {
+			if i < n && parsed[i].R == '\n' {
+				i++
+			}
+			if i >= n {
+				t.Fatalf("the output ended early: %q", out)
+			}
+			if want := cr.fg.Combine(cr.bg); parsed[i].R != cr.r || parsed[i].A != want {
+				t.Errorf("cell %d: got %q %v, want %q %v", i, parsed[i].R, parsed[i].A, cr.r, want)
+			}
+			i++
+		}
+	}
+}
+
+func TestCanvasLastDrawStats(t *testing.T) {
+	c := NewCanvasWithSize(4, 2)
+	var buf strings.Builder
+	c.SetOutput(&buf)
+	c.Draw()
+	if stats := c.LastDrawStats(); stats.Skipped || stats.CellsChanged != 8 || stats.CellsCompared != 0 || stats.BytesWritten != buf.Len() {
+		t.Errorf("first frame: got %+v, %d bytes written", stats, buf.Len())
+	}
+
+	buf.Reset()
+	c.WriteString(1, 0, Red, DefaultBackground, "ab")
+	c.Draw()
+	if stats := c.LastDrawStats(); stats.Skipped || stats.CellsChanged != 2 || stats.CellsCompared != 8 || stats.BytesWritten != buf.Len() {
+		t.Errorf("second frame: got %+v, %d bytes written", stats, buf.Len())
+	}
+
+	c.Draw()
+	if stats := c.LastDrawStats(); !stats.Skipped || stats.CellsChanged != 0 || stats.BytesWritten != 0 {
+		t.Errorf("unchanged frame: got %+v", stats)
+	}
+}
+
+func TestCanvasColorString(t *testing.T) {
+	if EnvNoColor {
+		t.Skip("NO_COLOR is set")
+	}
+	c := NewCanvasWithSize(4, 2)
+	c.FillRegion(0, 0, 4, 2, Red, Blue, ' ')
+	c.WriteString(0, 0, Red, Blue, "a日")
+	c.WriteString(0, 1, Red, Blue, "bc")
+	out := c.ColorString()
+	if !strings.HasSuffix(out, "\n"+NoColor) {
+		t.Errorf("expected a final reset, got %q", out)
+	}
+	if n := strings.Count(out, Red.Combine(Blue.Background()).String()); n != 1 {
+		t.Errorf("expected the colors to be emitted once, also across rows, got %d times in %q", n, out)
+	}
+	// The continuation cell of the wide rune is skipped
+	if got, want := StripColors(out), "a日 \nbc  \n"; got != want {
+		t.Errorf("got %q without the colors, want %q", got, want)
+	}
+}
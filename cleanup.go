@@ -0,0 +1,91 @@
+package vt
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+var (
+	// openTTYsMut guards openTTYs
+	openTTYsMut sync.Mutex
+
+	// openTTYs are the TTYs that have been opened and not yet closed, so
+	// that they can be restored by Exit
+	openTTYs = make(map[*TTY]struct{})
+
+	registerCleanupOnce sync.Once
+)
+
+// trackTTY registers an open TTY
+func trackTTY(tty *TTY) {
+	openTTYsMut.Lock()
+	openTTYs[tty] = struct{}{}
+	openTTYsMut.Unlock()
+}
+
+// untrackTTY unregisters a TTY that has been closed
+func untrackTTY(tty *TTY) {
+	openTTYsMut.Lock()
+	delete(openTTYs, tty)
+	openTTYsMut.Unlock()
+}
+
+// restoreTerminal turns off the terminal modes that have been changed by
+// this package, like mouse reporting and bracketed paste, shows the cursor
+// and closes the open TTYs, which takes the terminal out of raw mode
+func restoreTerminal() {
+	if modes.mouse.Load() {
+		modes.mouse.Store(false)
+		fmt.Print(disableMouse)
+	}
+	if modes.bracketedPaste.Load() {
+		SetBracketedPaste(false)
+	}
+	if modes.appCursorKeys.Load() {
+		SetApplicationCursorKeys(false)
+	}
+	if modes.reverseScreen.Load() {
+		SetReverseScreen(false)
+	}
+	if modes.lineWrapOff.Load() {
+		SetLineWrap(true)
+	}
+	if modes.cursorHidden.Load() {
+		ShowCursor(true)
+	}
+	openTTYsMut.Lock()
+	ttys := make([]*TTY, 0, len(openTTYs))
+	for tty := range openTTYs {
+		ttys = append(ttys, tty)
+	}
+	openTTYsMut.Unlock()
+	for _, tty := range ttys {
+		tty.Close()
+	}
+}
+
+// Exit restores the terminal and then exits the program with the given
+// status code. Deferred calls to Close are skipped by os.Exit, so use this
+// instead of os.Exit to not leave the terminal in raw mode, with the mouse
+// reporting or the cursor hidden.
+func Exit(code int) {
+	restoreTerminal()
+	os.Exit(code)
+}
+
+// RegisterCleanup makes the program restore the terminal before exiting
+// when it is interrupted or terminated by a signal, like SIGINT or SIGTERM,
+// in the same way as Exit. The exit status is 128 plus the signal number.
+// Calling RegisterCleanup more than once has no further effect.
+func RegisterCleanup() {
+	registerCleanupOnce.Do(func() {
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, cleanupSignals...)
+		go func() {
+			sig := <-sigChan
+			Exit(signalExitCode(sig))
+		}()
+	})
+}
@@ -0,0 +1,72 @@
+package vt
+
+// clipRect is a rectangle in canvas coordinates, see SetClip
+type clipRect struct {
+	x, y, w, h uint
+}
+
+// contains reports if (x, y) is within the rectangle
+func (r *clipRect) contains(x, y uint) bool {
+	return x >= r.x && y >= r.y && x-r.x < r.w && y-r.y < r.h
+}
+
+// intersect returns the part of r that is also within other
+func (r *clipRect) intersect(other *clipRect) *clipRect {
+	x, y := max(r.x, other.x), max(r.y, other.y)
+	x2, y2 := min(r.x+r.w, other.x+other.w), min(r.y+r.h, other.y+other.h)
+	return &clipRect{x, y, x2 - min(x, x2), y2 - min(y, y2)}
+}
+
+// SetClip restricts all writes to the canvas to the w x h rectangle with
+// its top left corner at (x, y), until ClearClip is called. Writes outside
+// of the rectangle are silently discarded. This applies to both the write
+// methods that are offset by the padding area, like WriteString, and the
+// ones that are not, like WriteRuneB and the *NoLock methods. The position
+// is offset by the padding area, if one is set.
+func (c *Canvas) SetClip(x, y, w, h uint) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.clip = &clipRect{x + c.padLeft, y + c.padTop, w, h}
+}
+
+// ClearClip removes the clip rectangle that is set with SetClip or
+// PushClip, so that the whole canvas can be written to again
+func (c *Canvas) ClearClip() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.clip = nil
+}
+
+// PushClip saves the current clip rectangle, and then restricts the writes
+// to the part of the given rectangle that is within it. This is useful for
+// containers that draw their children within their own bounds. Call PopClip
+// to go back to the saved clip rectangle.
+func (c *Canvas) PushClip(x, y, w, h uint) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.clipStack = append(c.clipStack, c.clip)
+	clip := &clipRect{x + c.padLeft, y + c.padTop, w, h}
+	if c.clip != nil {
+		clip = clip.intersect(c.clip)
+	}
+	c.clip = clip
+}
+
+// PopClip restores the clip rectangle that was saved by the last call to
+// PushClip. If nothing has been pushed, the clip rectangle is removed.
+func (c *Canvas) PopClip() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if n := len(c.clipStack); n > 0 {
+		c.clip = c.clipStack[n-1]
+		c.clipStack = c.clipStack[:n-1]
+		return
+	}
+	c.clip = nil
+}
+
+// inClip reports if (x, y), in canvas coordinates, may be written to.
+// The canvas mutex must be held.
+func (c *Canvas) inClip(x, y uint) bool {
+	return c.clip == nil || c.clip.contains(x, y)
+}
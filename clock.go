@@ -0,0 +1,135 @@
+package vt
+
+import (
+	"math"
+	"time"
+)
+
+// DrawDigitalClock writes t, formatted with the given time.Format layout, at
+// (x, y). An empty format defaults to "15:04:05". Wide runes count as two
+// columns and combining marks as none.
+// Returns the width and height of the drawn clock.
+func (c *Canvas) DrawDigitalClock(x, y uint, t time.Time, format string, fg, bg AttributeColor) (uint, uint) {
+	if format == "" {
+		format = "15:04:05"
+	}
+	return c.writeColumns(x, y, fg, bg, t.Format(format)), 1
+}
+
+// DrawAnalogClock draws a round clock face with hour, minute and second hands
+// using braille characters, with the top left corner at (x, y). r is the
+// radius in braille dots. The hour hand reaches r/2 dots from the center, the
+// minute hand r*3/4 and the second hand r. The face has tick marks at 12, 3,
+// 6 and 9. Returns the width and height of the bounding box, in cells.
+func (c *Canvas) DrawAnalogClock(x, y, r uint, t time.Time, fg, hourFg, minuteFg, secondFg, bg AttributeColor) (uint, uint) {
+	if r == 0 {
+		r = 1
+	}
+	size := int(2*r + 1)
+	b := newBrailleGrid(size, size)
+	center := int(r)
+	radius := float64(r)
+
+	// Face
+	steps := int(8 * radius)
+	for i := range steps {
+		a := 2 * math.Pi * float64(i) / float64(steps)
+		b.set(center+int(math.Round(radius*math.Sin(a))), center-int(math.Round(radius*math.Cos(a))), brailleFace)
+	}
+	// Tick marks at 12, 3, 6 and 9
+	for i := range 4 {
+		b.radial(center, float64(i)*math.Pi/2, radius*0.8, radius, brailleFace)
+	}
+
+	// Hands, drawn in order of priority so the second hand ends up on top
+	hour := float64(t.Hour()%12) + float64(t.Minute())/60
+	minute := float64(t.Minute()) + float64(t.Second())/60
+	second := float64(t.Second())
+	b.radial(center, hour/12*2*math.Pi, 0, radius/2, brailleHour)
+	b.radial(center, minute/60*2*math.Pi, 0, radius*3/4, brailleMinute)
+	b.radial(center, second/60*2*math.Pi, 0, radius, brailleSecond)
+
+	colors := [...]AttributeColor{fg, fg, hourFg, minuteFg, secondFg}
+	return b.draw(c, x, y, colors[:], bg)
+}
+
+// Layers in a brailleGrid, from lowest to highest priority
+const (
+	brailleNone = iota
+	brailleFace
+	brailleHour
+	brailleMinute
+	brailleSecond
+)
+
+// brailleBits maps a dot position within a braille cell (2 dots wide and
+// 4 dots tall) to its bit in the U+2800 braille block
+var brailleBits = [4][2]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// brailleGrid is a grid of dots, where each dot holds a layer number
+type brailleGrid struct {
+	dots []uint8
+	w, h int
+}
+
+func newBrailleGrid(w, h int) *brailleGrid {
+	return &brailleGrid{dots: make([]uint8, w*h), w: w, h: h}
+}
+
+// set sets the dot at (x, y) to the given layer, unless it is out of bounds
+func (b *brailleGrid) set(x, y int, layer uint8) {
+	if x < 0 || y < 0 || x >= b.w || y >= b.h {
+		return
+	}
+	b.dots[y*b.w+x] = layer
+}
+
+// radial draws a line from the distance from to the distance to, away from
+// the center of the grid, at angle a (radians, clockwise from 12 o'clock)
+func (b *brailleGrid) radial(center int, a, from, to float64, layer uint8) {
+	steps := int(math.Ceil((to - from) * 2))
+	for i := 0; i <= steps; i++ {
+		d := from
+		if steps > 0 {
+			d += (to - from) * float64(i) / float64(steps)
+		}
+		b.set(center+int(math.Round(d*math.Sin(a))), center-int(math.Round(d*math.Cos(a))), layer)
+	}
+}
+
+// draw writes the grid to the canvas as braille runes, coloring each cell
+// with the color of the highest layer within it.
+// Returns the width and height in cells.
+func (b *brailleGrid) draw(c *Canvas, x, y uint, colors []AttributeColor, bg AttributeColor) (uint, uint) {
+	cw, ch := (b.w+1)/2, (b.h+3)/4
+	for cy := range ch {
+		for cx := range cw {
+			var bits rune
+			var top uint8
+			for dy := range 4 {
+				for dx := range 2 {
+					px, py := cx*2+dx, cy*4+dy
+					if px >= b.w || py >= b.h {
+						continue
+					}
+					layer := b.dots[py*b.w+px]
+					if layer == brailleNone {
+						continue
+					}
+					bits |= brailleBits[dy][dx]
+					top = max(top, layer)
+				}
+			}
+			if bits == 0 {
+				continue
+			}
+			c.WriteRune(x+uint(cx), y+uint(cy), colors[top], bg, 0x2800+bits)
+		}
+	}
+	return uint(cw), uint(ch)
+}
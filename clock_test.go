@@ -0,0 +1,45 @@
+package vt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDrawDigitalClock(t *testing.T) {
+	c := NewCanvasWithSize(10, 1)
+	tm := time.Date(2024, 1, 2, 13, 4, 5, 0, time.UTC)
+	w, h := c.DrawDigitalClock(0, 0, tm, "", Default, Default)
+	if w != 8 || h != 1 {
+		t.Errorf("size: got (%d,%d), want (8,1)", w, h)
+	}
+	if got, want := c.String(), "13:04:05  \n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestDrawDigitalClockWideRunes(t *testing.T) {
+	c := NewCanvasWithSize(10, 1)
+	tm := time.Date(2024, 1, 2, 13, 4, 5, 0, time.UTC)
+	w, _ := c.DrawDigitalClock(0, 0, tm, "15時04分", Default, Default)
+	if w != 8 {
+		t.Errorf("width: got %d, want 8", w)
+	}
+	if got, want := c.String(), "13時 04分   \n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestDrawAnalogClock(t *testing.T) {
+	c := NewCanvasWithSize(20, 10)
+	tm := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+	w, h := c.DrawAnalogClock(0, 0, 8, tm, Default, Red, Green, Blue, Default)
+	if w != 9 || h != 5 {
+		t.Errorf("size: got (%d,%d), want (9,5)", w, h)
+	}
+	// Every drawn cell must be a braille rune
+	for i, cr := range c.chars {
+		if cr.r != 0 && (cr.r < 0x2800 || cr.r > 0x28FF) {
+			t.Fatalf("cell %d: unexpected rune %q", i, cr.r)
+		}
+	}
+}
@@ -1,11 +1,17 @@
 package main
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/xyproto/vt"
 )
 
 func main() {
-	vt.Init()
+	if err := vt.Init(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 
 	c := vt.NewCanvas()
 	c.FillBackground(vt.Blue)
@@ -1,12 +1,18 @@
 package main
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/xyproto/vt"
 )
 
 func main() {
 	// Initialize vt terminal settings
-	vt.Init()
+	if err := vt.Init(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 
 	// Prepare a canvas
 	c := vt.NewCanvas()
@@ -58,7 +58,10 @@ func Menu(title, titleColor string, choices []string, selectionDelay time.Durati
 		}
 	}()
 
-	vt.Init()
+	if err := vt.Init(); err != nil {
+		tty.Close()
+		panic(err)
+	}
 	defer vt.Close()
 
 	// The loop time that is aimed for
@@ -48,7 +48,9 @@ func main() {
 		}
 	}()
 
-	vt.Init()
+	if err := vt.Init(); err != nil {
+		log.Fatalln(err)
+	}
 	defer func() {
 		vt.Clear()
 		vt.Close()
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/xyproto/vt"
+)
+
+func main() {
+	tty, err := vt.NewTTY()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	caps := vt.ProbeCapabilities(tty)
+	state := vt.DebugState(tty)
+	tty.Close()
+	fmt.Print(caps)
+	fmt.Println()
+	fmt.Print(state)
+}
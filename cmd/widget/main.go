@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"github.com/xyproto/vt"
 	"os"
 	"sync"
@@ -71,7 +72,8 @@ func main() {
 			time.Sleep(100 * time.Millisecond)
 			//vt.Reset()
 
-			vt.Init()
+			// The terminal was checked by the first Init
+			vt.ForceInit()
 			c = vt.NewCanvas()
 			draw(c)
 
@@ -81,7 +83,10 @@ func main() {
 
 	resizeMut.Lock()
 
-	vt.Init()
+	if err := vt.Init(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 	defer vt.Close()
 
 	c.Clear()
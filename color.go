@@ -9,7 +9,7 @@ import (
 )
 
 // AttributeColor represents a terminal color/attribute value
-type AttributeColor uint32
+type AttributeColor uint64
 
 const (
 	// Non-color attributes
@@ -64,6 +64,12 @@ const (
 
 	Default           AttributeColor = 39
 	DefaultBackground AttributeColor = 49
+
+	// Transparent is a marker for cells of a canvas that is blitted onto
+	// another one. A Transparent foreground or background color keeps the
+	// color of the cell beneath, see BlitTransparent. It has no escape
+	// sequence, and combining it with another color gives that color.
+	Transparent AttributeColor = 0xFFFF
 )
 
 var (
@@ -85,12 +91,16 @@ var envResetSeq = func() string {
 }()
 
 // Bit flags used in the upper bits of AttributeColor to signal extended modes.
-// Layout (uint32):
+// Layout (the lower 32 bits):
 //
 //	bit 31 = 1  → extended (256-color or true-color)
 //	bit 30 = 1  → background, 0 = foreground
 //	bit 29 = 1  → true-color (24-bit RGB in bits 0–23)
 //	bit 29 = 0  → 256-color  (palette index in bits 0–7)
+//	bits 28–25  → Bold, Italic, Underscore and Reverse, combined with Combine
+//
+// When two extended values are combined with Combine, the second one is
+// kept in the upper 32 bits, see pairShift.
 const (
 	extendedFlag  = uint32(1 << 31)
 	bgFlag        = uint32(1 << 30)
@@ -98,8 +108,14 @@ const (
 	boldFlag      = uint32(1 << 28)
 	italicFlag    = uint32(1 << 27)
 	underlineFlag = uint32(1 << 26)
+	reverseFlag   = uint32(1 << 25)
 )
 
+// pairShift is the bit position of the second value of a pair of extended
+// values that are combined with Combine. The first value is kept in the
+// lower 32 bits.
+const pairShift = 32
+
 // DarkColorMap maps color names to AttributeColor values for dark terminals
 var DarkColorMap = map[string]AttributeColor{
 	"black":        Black,
@@ -182,7 +198,7 @@ var ansiEscapes [256]string
 
 // extCache caches escape sequences for AttributeColor values outside the 0–255
 // range: true-color (bit 31 + bit 29 set), 256-color (bit 31 set), and
-// combined two-attribute values (val > 0xFFFF, bit 31 clear).
+// combined attribute values (val > 0xFFFF, bit 31 clear).
 var extCache sync.Map
 
 func init() {
@@ -201,6 +217,10 @@ func (ac AttributeColor) Tail() uint32 {
 
 // Background converts a foreground color to the corresponding background attribute
 func (ac AttributeColor) Background() AttributeColor {
+	if ac.isPair() {
+		// A combined pair already holds its own foreground and background
+		return ac
+	}
 	val := uint32(ac)
 	if val&extendedFlag != 0 {
 		// 256-color or true-color: set the bg flag
@@ -226,17 +246,32 @@ func (ac AttributeColor) Background() AttributeColor {
 // allocation. Extended values (true-color, 256-color, combined attributes) are
 // computed once and memoized in extCache.
 // Returns "" when NO_COLOR is set.
+// If a color remapping function has been set with SetColorRemap, it is
+// applied first.
 func (ac AttributeColor) String() string {
 	if EnvNoColor {
 		return ""
 	}
-	val := uint32(ac)
+	if remap := colorRemap.Load(); remap != nil {
+		return ac.remappedString(*remap)
+	}
+	return ac.escape()
+}
 
+// escape returns the VT100 escape sequence for this color/attribute,
+// without applying any color remapping
+func (ac AttributeColor) escape() string {
 	// Fast path: standard ANSI attribute/color codes (the vast majority of calls)
-	if val < 256 {
-		return ansiEscapes[val]
+	if ac < 256 {
+		return ansiEscapes[ac]
 	}
 
+	if ac.isPair() {
+		first, second := ac.pair()
+		return first.escape() + second.escape()
+	}
+
+	val := uint32(ac)
 	if cached, ok := extCache.Load(val); ok {
 		return cached.(string)
 	}
@@ -249,7 +284,7 @@ func (ac AttributeColor) String() string {
 			r := uint8((val >> 16) & 0xFF)
 			g := uint8((val >> 8) & 0xFF)
 			b := uint8(val & 0xFF)
-			if hasTrueColorEnv {
+			if useTrueColor() {
 				// Terminal supports 24-bit color
 				if isBg {
 					result = fmt.Sprintf("\033[48;2;%d;%d;%dm", r, g, b)
@@ -283,31 +318,47 @@ func (ac AttributeColor) String() string {
 			}
 		}
 	} else if val > 0xFFFF {
-		// Combined two-attribute value: primary in bits 0–15, secondary in bits 16–31
-		primary := val & 0xFFFF
-		secondary := (val >> 16) & 0xFFFF
-		result = fmt.Sprintf(attributeTemplate, strconv.FormatUint(uint64(primary), 10)+";"+strconv.FormatUint(uint64(secondary), 10))
+		// Combined value: up to four attribute codes, see packShifts
+		codes := ac.codes()
+		strs := make([]string, len(codes))
+		for i, code := range codes {
+			strs[i] = strconv.FormatUint(uint64(code), 10)
+		}
+		result = fmt.Sprintf(attributeTemplate, strings.Join(strs, ";"))
+	} else if ac == Transparent {
+		result = ""
 	} else {
 		// Single attribute code outside 0–255 (uncommon)
 		result = fmt.Sprintf(attributeTemplate, strconv.FormatUint(uint64(val), 10))
 	}
 
-	// Prepend SGR attributes for any attribute flags set on an extended color.
-	if val&extendedFlag != 0 {
-		if val&boldFlag != 0 {
-			result = "\033[1m" + result
-		}
-		if val&italicFlag != 0 {
-			result = "\033[3m" + result
-		}
-		if val&underlineFlag != 0 {
-			result = "\033[4m" + result
-		}
-	}
+	result = flagEscapes(val) + result
 	extCache.Store(val, result)
 	return result
 }
 
+// flagEscapes returns the SGR attributes for the attribute flags that are
+// set on an extended color, which are emitted before the color itself
+func flagEscapes(val uint32) string {
+	if val&extendedFlag == 0 {
+		return ""
+	}
+	var result string
+	if val&boldFlag != 0 {
+		result = "\033[1m" + result
+	}
+	if val&italicFlag != 0 {
+		result = "\033[3m" + result
+	}
+	if val&underlineFlag != 0 {
+		result = "\033[4m" + result
+	}
+	if val&reverseFlag != 0 {
+		result = "\033[7m" + result
+	}
+	return result
+}
+
 // Color256 returns an AttributeColor for the given xterm 256-color foreground index (0–255).
 // Use Has256Colors() to check whether the terminal supports this.
 func Color256(n uint8) AttributeColor {
@@ -320,6 +371,11 @@ func Background256(n uint8) AttributeColor {
 	return AttributeColor(uint32(1<<31) | uint32(1<<30) | uint32(n))
 }
 
+// BackgroundColor256 is the same as Background256
+func BackgroundColor256(n uint8) AttributeColor {
+	return Background256(n)
+}
+
 // Wrap returns text wrapped with this color's escape sequence and a trailing reset.
 // Returns text unchanged when NO_COLOR is set.
 func (ac AttributeColor) Wrap(text string) string {
@@ -357,44 +413,138 @@ func (ac AttributeColor) Error(text string) {
 	fmt.Fprintln(os.Stderr, ac.Wrap(text))
 }
 
-// Combine packs two AttributeColor values into one
-func (ac AttributeColor) Combine(other AttributeColor) AttributeColor {
-	if ac == 0 {
-		return other
+// packShifts are the bit positions of the attribute codes in a combined
+// value, in the order they were added. Two codes are packed as
+// primary | secondary<<16, and up to two more codes fill the remaining bytes.
+var packShifts = [...]uint32{0, 16, 8, 24}
+
+// codes returns the standard attribute codes that ac consists of, or nil if
+// ac is an extended color or holds a code that does not fit in a byte
+func (ac AttributeColor) codes() []uint32 {
+	val := uint32(ac)
+	if val&extendedFlag != 0 || (val >= 256 && val <= 0xFFFF) {
+		return nil
 	}
-	if other == 0 {
-		return ac
+	if val < 256 {
+		return []uint32{val}
 	}
+	var codes []uint32
+	for _, shift := range packShifts {
+		if code := (val >> shift) & 0xFF; code != 0 {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
 
-	// When combining an extended (256-color or true-color) value with the
-	// Bold or Italic attribute, set the corresponding flag bit on the
-	// extended color so the color encoding survives. Plain truncation via
-	// & 0xFFFF would strip extendedFlag and produce a meaningless SGR.
-	if uint32(ac)&extendedFlag != 0 && other == Bold {
-		return AttributeColor(uint32(ac) | boldFlag)
+// packCodes packs up to four standard attribute codes into one value.
+// Returns false if there are too many codes.
+func packCodes(codes []uint32) (AttributeColor, bool) {
+	if len(codes) > len(packShifts) || (len(codes) == len(packShifts) && codes[3] >= 128) {
+		return 0, false // the last code must not set extendedFlag
 	}
-	if uint32(other)&extendedFlag != 0 && ac == Bold {
-		return AttributeColor(uint32(other) | boldFlag)
+	var val uint32
+	for i, code := range codes {
+		val |= code << packShifts[i]
 	}
-	if uint32(ac)&extendedFlag != 0 && other == Italic {
-		return AttributeColor(uint32(ac) | italicFlag)
+	return AttributeColor(val), true
+}
+
+// attributeFlags returns the flag bits for setting the attributes of ac on
+// an extended color, or false if ac is not made up of only Bold, Italic,
+// Underscore and Reverse
+func (ac AttributeColor) attributeFlags() (uint32, bool) {
+	codes := ac.codes()
+	if codes == nil {
+		return 0, false
+	}
+	var flags uint32
+	for _, code := range codes {
+		switch AttributeColor(code) {
+		case Bold:
+			flags |= boldFlag
+		case Italic:
+			flags |= italicFlag
+		case Underscore:
+			flags |= underlineFlag
+		case Reverse:
+			flags |= reverseFlag
+		default:
+			return 0, false
+		}
 	}
-	if uint32(other)&extendedFlag != 0 && ac == Italic {
-		return AttributeColor(uint32(other) | italicFlag)
+	return flags, true
+}
+
+// Combine packs two AttributeColor values into one. Up to four standard
+// attributes and colors can be combined, and an extended (256-color or
+// true-color) value can be combined with Bold, Italic, Underscore and Reverse.
+// Two extended values, like a true-color foreground and a true-color
+// background, are kept side by side, but a pair can not be combined with a
+// third extended value.
+func (ac AttributeColor) Combine(other AttributeColor) AttributeColor {
+	if ac == 0 || ac == Transparent {
+		return other
 	}
-	if uint32(ac)&extendedFlag != 0 && other == Underscore {
-		return AttributeColor(uint32(ac) | underlineFlag)
+	if other == 0 || other == Transparent {
+		return ac
 	}
-	if uint32(other)&extendedFlag != 0 && ac == Underscore {
-		return AttributeColor(uint32(other) | underlineFlag)
+
+	// When combining an extended (256-color or true-color) value with the
+	// Bold, Italic, Underscore or Reverse attribute, set the corresponding flag bit on
+	// the extended color so the color encoding survives. Plain truncation via
+	// & 0xFFFF would strip extendedFlag and produce a meaningless SGR.
+	a, o := uint32(ac), uint32(other)
+	switch {
+	case a&extendedFlag != 0 && o&extendedFlag == 0:
+		if flags, ok := other.attributeFlags(); ok {
+			return ac | AttributeColor(flags)
+		}
+	case o&extendedFlag != 0 && a&extendedFlag == 0:
+		if flags, ok := ac.attributeFlags(); ok {
+			return other | AttributeColor(flags)
+		}
+	case a&extendedFlag != 0 && o&extendedFlag != 0:
+		if ac.isPair() || other.isPair() {
+			return ac // there is no room for a third extended value
+		}
+		return ac | other<<pairShift
+	case a&extendedFlag == 0 && o&extendedFlag == 0:
+		codesA, codesO := ac.codes(), other.codes()
+		if codesA != nil && codesO != nil {
+			if combined, ok := packCodes(append(codesA, codesO...)); ok {
+				return combined
+			}
+		}
 	}
 
-	val1 := uint32(ac) & 0xFFFF
-	val2 := uint32(other) & 0xFFFF
+	val1 := a & 0xFFFF
+	val2 := o & 0xFFFF
 
 	return AttributeColor(val1 | (val2 << 16))
 }
 
+// isPair reports if ac is a pair of extended values, combined with Combine
+func (ac AttributeColor) isPair() bool {
+	return ac>>pairShift != 0
+}
+
+// pair returns the two values that were combined into a pair
+func (ac AttributeColor) pair() (AttributeColor, AttributeColor) {
+	return ac & (1<<pairShift - 1), ac >> pairShift
+}
+
+// CombineAll combines all the given colors and attributes into one, as by
+// Combine. This is useful for building a style from a list, for instance
+// from a configuration file.
+func CombineAll(colors ...AttributeColor) AttributeColor {
+	var combined AttributeColor
+	for _, ac := range colors {
+		combined = combined.Combine(ac)
+	}
+	return combined
+}
+
 // Bright returns a new AttributeColor with the Bright attribute combined in
 func (ac AttributeColor) Bright() AttributeColor {
 	return ac.Combine(Bright)
@@ -428,6 +578,13 @@ func TrueColor(r, g, b uint8) AttributeColor {
 	return AttributeColor(extendedFlag | trueColorFlag | uint32(r)<<16 | uint32(g)<<8 | uint32(b))
 }
 
+// RGB is the same as TrueColor. Use Background to get the background color.
+// The canvas keeps the foreground and background colors of each cell
+// apart, so both can be true colors at the same time.
+func RGB(r, g, b uint8) AttributeColor {
+	return TrueColor(r, g, b)
+}
+
 // TrueBackground returns a true-color (24-bit) background AttributeColor for the given RGB values.
 func TrueBackground(r, g, b uint8) AttributeColor {
 	return AttributeColor(extendedFlag | trueColorFlag | bgFlag | uint32(r)<<16 | uint32(g)<<8 | uint32(b))
@@ -436,16 +593,20 @@ func TrueBackground(r, g, b uint8) AttributeColor {
 // parseHexColor parses a hex color string ("#rrggbb", "#rgb", "rrggbb", or "rgb")
 // and returns the red, green, and blue components.
 func parseHexColor(s string) (r, g, b uint8, err error) {
-	s = strings.TrimPrefix(s, "#")
-	if len(s) == 3 {
+	orig := s
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	switch len(s) {
+	case 3:
 		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	case 8:
+		s = s[:6] // the alpha channel is ignored
 	}
 	if len(s) != 6 {
-		return 0, 0, 0, fmt.Errorf("invalid hex color %q: expected #rrggbb or #rgb", "#"+s)
+		return 0, 0, 0, fmt.Errorf("invalid hex color %q: expected #rgb, #rrggbb or #rrggbbaa", orig)
 	}
 	v, err := strconv.ParseUint(s, 16, 32)
 	if err != nil {
-		return 0, 0, 0, fmt.Errorf("invalid hex color %q: %w", "#"+s, err)
+		return 0, 0, 0, fmt.Errorf("invalid hex color %q: %w", orig, err)
 	}
 	return uint8(v >> 16), uint8((v >> 8) & 0xFF), uint8(v & 0xFF), nil
 }
@@ -497,7 +658,8 @@ func nearestANSI16(r, g, b uint8) AttributeColor {
 }
 
 // ColorFromHex parses a hex color string and returns a true-color foreground AttributeColor.
-// Accepted formats: "#rrggbb", "#rgb", "rrggbb", "rgb".
+// Accepted formats: "#rrggbb", "#rgb", "#rrggbbaa", with or without the "#".
+// Surrounding whitespace is ignored, and so is the alpha channel.
 func ColorFromHex(s string) (AttributeColor, error) {
 	r, g, b, err := parseHexColor(s)
 	if err != nil {
@@ -506,8 +668,24 @@ func ColorFromHex(s string) (AttributeColor, error) {
 	return TrueColor(r, g, b), nil
 }
 
+// ParseHexColor parses a hex color string, like "#ff8800", and returns a
+// true-color foreground AttributeColor. It is the same as ColorFromHex.
+func ParseHexColor(s string) (AttributeColor, error) {
+	return ColorFromHex(s)
+}
+
+// MustParseHexColor is like ParseHexColor, but panics if s is not a valid
+// hex color. It is meant for colors that are known at compile time.
+func MustParseHexColor(s string) AttributeColor {
+	c, err := ParseHexColor(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
 // BackgroundFromHex parses a hex color string and returns a true-color background AttributeColor.
-// Accepted formats: "#rrggbb", "#rgb", "rrggbb", "rgb".
+// Accepted formats are the same as for ColorFromHex.
 func BackgroundFromHex(s string) (AttributeColor, error) {
 	r, g, b, err := parseHexColor(s)
 	if err != nil {
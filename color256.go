@@ -9,10 +9,23 @@ var hasTrueColorEnv = func() bool {
 	return ct == "truecolor" || ct == "24bit"
 }()
 
+// useTrueColor reports if 24-bit color escapes should be emitted, which is
+// when $COLORTERM asks for it, or when a probe found support for it and was
+// applied with Capabilities.Apply
+func useTrueColor() bool {
+	return hasTrueColorEnv || probedTrueColor.Load()
+}
+
 // HasTrueColor returns true when the terminal supports 24-bit true color
-// (i.e. $COLORTERM is "truecolor" or "24bit").
+// (i.e. $COLORTERM is "truecolor" or "24bit", the terminfo entry for $TERM
+// has 2^24 colors, like xterm-direct, or Capabilities.Apply was called with
+// a probe that found it).
 func HasTrueColor() bool {
-	return hasTrueColorEnv
+	if useTrueColor() {
+		return true
+	}
+	colors, _ := TerminfoColors()
+	return colors >= 1<<24
 }
 
 // Color256ToRGB returns the approximate RGB values for an xterm-256color palette index.
@@ -62,6 +75,24 @@ func NearestColor256(r, g, b uint8) AttributeColor {
 	return Color256(best)
 }
 
+// Color256FromRGB returns the 256-color foreground AttributeColor for the
+// entry in the 6×6×6 color cube (palette indices 16–231) that is closest to
+// (r, g, b). Unlike NearestColor256, the standard colors and the grayscale
+// ramp are never chosen, since their RGB values vary between terminals.
+func Color256FromRGB(r, g, b uint8) AttributeColor {
+	snap := func(v uint8) uint8 {
+		switch {
+		case v < 48:
+			return 0
+		case v < 115:
+			return 1
+		default:
+			return (v - 35) / 40
+		}
+	}
+	return ColorCube(snap(r), snap(g), snap(b))
+}
+
 // Grayscale256 returns a 256-color foreground AttributeColor from the 24-step
 // grayscale ramp (palette indices 232–255). level 0 is near-black (rgb 8,8,8)
 // and level 23 is near-white (rgb 238,238,238). Values above 23 are clamped.
@@ -91,14 +122,15 @@ func ColorCube(r, g, b uint8) AttributeColor {
 // BestColor returns the most faithful foreground AttributeColor for (r, g, b)
 // that the current terminal can display:
 //   - Default (no color) if NO_COLOR is set
-//   - 24-bit true color if $COLORTERM is "truecolor" or "24bit"
+//   - 24-bit true color if $COLORTERM is "truecolor" or "24bit", or if
+//     Capabilities.Apply was called with a probe that found it
 //   - nearest xterm-256color entry if $TERM contains "256color" or is "xterm-kitty"
 //   - nearest ANSI-16 color otherwise
 func BestColor(r, g, b uint8) AttributeColor {
 	if EnvNoColor {
 		return Default
 	}
-	if hasTrueColorEnv {
+	if useTrueColor() {
 		return TrueColor(r, g, b)
 	}
 	if Has256Colors() {
@@ -0,0 +1,134 @@
+package vt
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// colorRemap holds the function set with SetColorRemap, or nil
+var colorRemap atomic.Pointer[func(AttributeColor) AttributeColor]
+
+// remapCache caches escape sequences for remapped colors. It is cleared
+// every time the remapping function changes.
+var remapCache sync.Map
+
+// SetColorRemap sets a function that is applied to every AttributeColor
+// when it is rendered, both by AttributeColor.String and when drawing a
+// Canvas. This makes it possible to switch the palette of an application,
+// for instance to a color-blind safe one, without changing every Write call.
+// The remapped color is then downgraded to what the terminal supports, as
+// usual. Pass nil to remove the remapping.
+func SetColorRemap(f func(AttributeColor) AttributeColor) {
+	if f == nil {
+		colorRemap.Store(nil)
+	} else {
+		colorRemap.Store(&f)
+	}
+	remapCache.Clear()
+	RebuildTagReplacers()
+}
+
+// remappedString returns the escape sequence for ac after applying remap.
+// Combined values (such as a foreground and a background packed by Combine)
+// are split, so that each attribute is remapped on its own.
+func (ac AttributeColor) remappedString(remap func(AttributeColor) AttributeColor) string {
+	if ac.isPair() {
+		first, second := ac.pair()
+		return first.remappedString(remap) + second.remappedString(remap)
+	}
+	val := uint32(ac)
+	if cached, ok := remapCache.Load(val); ok {
+		return cached.(string)
+	}
+	var result string
+	if val&extendedFlag == 0 && val > 0xFFFF {
+		var (
+			combined AttributeColor
+			escapes  string
+		)
+		for _, code := range ac.codes() {
+			remapped := remap(AttributeColor(code))
+			if remapped < 256 {
+				combined = combined.Combine(remapped)
+			} else {
+				escapes += remapped.escape()
+			}
+		}
+		result = escapes
+		if combined != 0 {
+			result = combined.escape() + escapes
+		}
+	} else {
+		result = remap(ac).escape()
+	}
+	remapCache.Store(val, result)
+	return result
+}
+
+// Palette maps the 16 standard ANSI foreground colors to replacement colors
+type Palette map[AttributeColor]AttributeColor
+
+// Color-blind safe palettes, based on the Okabe-Ito palette. Colors that
+// are easy to confuse with the given type of color blindness are replaced
+// with colors that differ in both hue and lightness.
+var (
+	DeuteranopiaPalette = newPalette([8]AttributeColor{
+		TrueColor(0, 0, 0),       // black
+		TrueColor(213, 94, 0),    // red → vermillion
+		TrueColor(0, 114, 178),   // green → blue
+		TrueColor(240, 228, 66),  // yellow
+		TrueColor(86, 180, 233),  // blue → sky blue
+		TrueColor(204, 121, 167), // magenta → reddish purple
+		TrueColor(0, 158, 115),   // cyan → bluish green
+		TrueColor(229, 229, 229), // light gray
+	})
+	ProtanopiaPalette = newPalette([8]AttributeColor{
+		TrueColor(0, 0, 0),       // black
+		TrueColor(230, 159, 0),   // red → orange
+		TrueColor(0, 114, 178),   // green → blue
+		TrueColor(240, 228, 66),  // yellow
+		TrueColor(86, 180, 233),  // blue → sky blue
+		TrueColor(204, 121, 167), // magenta → reddish purple
+		TrueColor(0, 158, 115),   // cyan → bluish green
+		TrueColor(229, 229, 229), // light gray
+	})
+	TritanopiaPalette = newPalette([8]AttributeColor{
+		TrueColor(0, 0, 0),       // black
+		TrueColor(213, 94, 0),    // red → vermillion
+		TrueColor(0, 158, 115),   // green → bluish green
+		TrueColor(204, 121, 167), // yellow → reddish purple
+		TrueColor(0, 114, 178),   // blue
+		TrueColor(230, 159, 0),   // magenta → orange
+		TrueColor(86, 180, 233),  // cyan → sky blue
+		TrueColor(229, 229, 229), // light gray
+	})
+)
+
+// newPalette builds a Palette from replacements for the 8 standard colors
+// (Black to LightGray). The bright variants are lightened versions of these.
+func newPalette(base [8]AttributeColor) Palette {
+	p := make(Palette, 16)
+	for i, c := range base {
+		p[Black+AttributeColor(i)] = c
+		p[DarkGray+AttributeColor(i)] = Lighten(c, 0.25)
+	}
+	return p
+}
+
+// Remap returns a function for SetColorRemap that replaces the standard
+// foreground colors in the palette, and their background counterparts.
+// All other colors and attributes are returned unchanged.
+func (p Palette) Remap() func(AttributeColor) AttributeColor {
+	return func(ac AttributeColor) AttributeColor {
+		if mapped, ok := p[ac]; ok {
+			return mapped
+		}
+		val := uint32(ac)
+		if (val >= 40 && val <= 47) || (val >= 100 && val <= 107) {
+			if mapped, ok := p[AttributeColor(val-10)]; ok {
+				return mapped.Background()
+			}
+		}
+		return ac
+	}
+}
@@ -0,0 +1,42 @@
+package vt
+
+import "testing"
+
+func TestSetColorRemap(t *testing.T) {
+	if EnvNoColor {
+		t.Skip("NO_COLOR is set")
+	}
+	defer SetColorRemap(nil)
+	SetColorRemap(func(ac AttributeColor) AttributeColor {
+		switch ac {
+		case Red:
+			return Blue
+		case BackgroundGreen:
+			return BackgroundYellow
+		}
+		return ac
+	})
+	if got, want := Red.String(), Blue.escape(); got != want {
+		t.Errorf("Red: got %q, want %q", got, want)
+	}
+	if got, want := Red.Combine(BackgroundGreen).String(), Blue.Combine(BackgroundYellow).escape(); got != want {
+		t.Errorf("combined: got %q, want %q", got, want)
+	}
+	SetColorRemap(nil)
+	if got, want := Red.String(), "\033[31m"; got != want {
+		t.Errorf("after removing the remap: got %q, want %q", got, want)
+	}
+}
+
+func TestPaletteRemap(t *testing.T) {
+	remap := DeuteranopiaPalette.Remap()
+	if got := remap(Red); got != TrueColor(213, 94, 0) {
+		t.Errorf("Red: got %d", got)
+	}
+	if got := remap(BackgroundRed); got != TrueBackground(213, 94, 0) {
+		t.Errorf("BackgroundRed: got %d", got)
+	}
+	if got := remap(Bold); got != Bold {
+		t.Errorf("Bold should be unchanged, got %d", got)
+	}
+}
@@ -0,0 +1,116 @@
+package vt
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	// dictMut guards colorDictionaries and dictionaryOrder, and serializes
+	// rebuilding the tag replacers
+	dictMut sync.Mutex
+
+	// colorDictionaries holds the registered color dictionaries, by name
+	colorDictionaries = make(map[string]map[string]AttributeColor)
+
+	// dictionaryOrder holds the dictionary names in registration order
+	dictionaryOrder []string
+)
+
+// RegisterColorDictionary registers a named dictionary of colors, for
+// instance project-specific names like "brand", "accent" or "muted".
+// The colors can then be used by ParseColor and in tags, either with the
+// dictionary name as a prefix, like <brand:accent>, or without it, like
+// <accent>. A prefixed name always refers to the given dictionary, while
+// an unprefixed name is looked up in the built-in color maps first, and
+// then in the dictionaries in the order they were registered. Registering
+// a dictionary with a name that is already in use replaces it. The map is
+// copied, so changing m afterwards has no effect.
+func RegisterColorDictionary(name string, m map[string]AttributeColor) {
+	dictMut.Lock()
+	defer dictMut.Unlock()
+	if _, exists := colorDictionaries[name]; !exists {
+		dictionaryOrder = append(dictionaryOrder, name)
+	}
+	colorDictionaries[name] = maps.Clone(m)
+	rebuildTagReplacers()
+}
+
+// UnregisterColorDictionary removes a color dictionary that was registered
+// with RegisterColorDictionary, and rebuilds the tag replacers
+func UnregisterColorDictionary(name string) {
+	dictMut.Lock()
+	defer dictMut.Unlock()
+	if _, exists := colorDictionaries[name]; !exists {
+		return
+	}
+	delete(colorDictionaries, name)
+	dictionaryOrder = slices.DeleteFunc(dictionaryOrder, func(s string) bool { return s == name })
+	rebuildTagReplacers()
+}
+
+// withDictionaries returns base extended with the colors of the registered
+// dictionaries, or base itself if there are none. Must be called with
+// dictMut held.
+func withDictionaries(base map[string]AttributeColor) map[string]AttributeColor {
+	if len(dictionaryOrder) == 0 {
+		return base
+	}
+	merged := maps.Clone(base)
+	for _, name := range dictionaryOrder {
+		for key, value := range colorDictionaries[name] {
+			merged[name+":"+key] = value
+			if _, exists := merged[key]; !exists {
+				merged[key] = value
+			}
+		}
+	}
+	return merged
+}
+
+// ParseColor returns the color for the given name or hex color string.
+// Hex colors ("#rrggbb" or "#rgb") give a true color. Names are looked up
+// in LightColorMap and then in the registered color dictionaries, and a
+// name with a dictionary prefix, like "brand:accent", is looked up in that
+// dictionary only. If the name is not found as given, it is looked up
+// again in lowercase.
+func ParseColor(s string) (AttributeColor, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "#") {
+		r, g, b, err := parseHexColor(s)
+		if err != nil {
+			return Default, err
+		}
+		return TrueColor(r, g, b), nil
+	}
+	dictMut.Lock()
+	defer dictMut.Unlock()
+	if ac, ok := lookupColor(s); ok {
+		return ac, nil
+	}
+	if ac, ok := lookupColor(strings.ToLower(s)); ok {
+		return ac, nil
+	}
+	return Default, fmt.Errorf("unknown color %q", s)
+}
+
+// lookupColor looks up a color name, as described for ParseColor.
+// Must be called with dictMut held.
+func lookupColor(name string) (AttributeColor, bool) {
+	if dictName, key, found := strings.Cut(name, ":"); found {
+		ac, ok := colorDictionaries[dictName][key]
+		return ac, ok
+	}
+	if ac, ok := LightColorMap[name]; ok {
+		return ac, true
+	}
+	for _, dictName := range dictionaryOrder {
+		if ac, ok := colorDictionaries[dictName][name]; ok {
+			return ac, true
+		}
+	}
+	return Default, false
+}
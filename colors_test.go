@@ -191,3 +191,126 @@ func TestNearestANSI16(t *testing.T) {
 		}
 	}
 }
+
+func TestColorDictionaries(t *testing.T) {
+	if EnvNoColor {
+		t.Skip("NO_COLOR is set")
+	}
+	brand := TrueColor(1, 2, 3)
+	other := TrueColor(4, 5, 6)
+	early := NewTextOutput(true, true) // created before the dictionaries
+	RegisterColorDictionary("brand", map[string]AttributeColor{"accent": brand, "red": brand})
+	RegisterColorDictionary("other", map[string]AttributeColor{"accent": other, "Muted": other})
+	defer UnregisterColorDictionary("other")
+	defer UnregisterColorDictionary("brand")
+
+	for _, tc := range []struct {
+		name string
+		want AttributeColor
+	}{
+		{"accent", brand},       // first registered dictionary wins
+		{"other:accent", other}, // an explicit prefix wins
+		{"red", LightColorMap["red"]},
+		{"brand:red", brand},
+		{"Muted", other},
+		{"#ff0000", TrueColor(255, 0, 0)},
+		{"RED", LightColorMap["red"]},
+	} {
+		got, err := ParseColor(tc.name)
+		if err != nil || got != tc.want {
+			t.Errorf("ParseColor(%q) = (%v, %v), want %v", tc.name, got, err, tc.want)
+		}
+	}
+	if _, err := ParseColor("brand:muted"); err == nil {
+		t.Error("expected an error for a name missing from the given dictionary")
+	}
+
+	o := NewTextOutput(true, true)
+	if got, want := o.Tags("<brand:accent>x<off>"), brand.String()+"x"+NoColor; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := early.Tags("<brand:accent>x<off>"), brand.String()+"x"+NoColor; got != want {
+		t.Errorf("expected an existing TextOutput to see the new names, got %q, want %q", got, want)
+	}
+	if got, want := o.Tags("<accent>x</accent>"), brand.String()+"x"+NoColor; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	UnregisterColorDictionary("brand")
+	if _, err := ParseColor("accent"); err != nil {
+		t.Errorf("expected the other dictionary to take over, got %v", err)
+	}
+	if got := NewTextOutput(true, true).Tags("<brand:accent>"); got != "<brand:accent>" {
+		t.Errorf("expected the unregistered tag to be left as is, got %q", got)
+	}
+}
+
+func TestCombineTrueColors(t *testing.T) {
+	if EnvNoColor {
+		t.Skip("NO_COLOR is set")
+	}
+	fg, bg := TrueColor(10, 20, 30), TrueBackground(40, 50, 60)
+	combined := fg.Combine(bg)
+	if got, want := combined.String(), fg.String()+bg.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if again := fg.Combine(bg); again != combined {
+		t.Errorf("expected the same pair to give the same value, got %d and %d", combined, again)
+	}
+	if other := fg.Combine(TrueBackground(40, 50, 61)); other == combined {
+		t.Error("expected another pair to give another value")
+	}
+	if got, want := combined.Combine(Bold).String(), "\033[1m"+fg.String()+bg.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if combined.Background() != combined || Is256Color(combined) {
+		t.Errorf("unexpected handling of the combined value %d", combined)
+	}
+	if r, g, b, ok := ToRGB(combined); !ok || r != 10 || g != 20 || b != 30 {
+		t.Errorf("got (%d, %d, %d, %v), want the foreground", r, g, b, ok)
+	}
+	if first, second := combined.pair(); first != fg || second != bg {
+		t.Errorf("expected the pair to hold both values, got %d and %d", first, second)
+	}
+	if got := combined.Combine(TrueColor(1, 2, 3)); got != combined {
+		t.Errorf("expected a third extended value to be left out, got %d", got)
+	}
+}
+
+func TestCombineAll(t *testing.T) {
+	if EnvNoColor {
+		t.Skip("NO_COLOR is set")
+	}
+	if got, want := CombineAll(Bold, Red, Underscore, BackgroundBlue).String(), "\033[1;31;4;44m"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := Bold.Combine(White), CombineAll(Bold, White); got != want {
+		t.Errorf("two attributes should combine as before: got %d, want %d", got, want)
+	}
+	tc := TrueColor(1, 2, 3)
+	if got, want := CombineAll(Bold, Underscore, tc), tc.Combine(Bold).Combine(Underscore); got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+	if CombineAll() != 0 || CombineAll(Red) != Red {
+		t.Error("unexpected result for zero or one color")
+	}
+}
+
+func TestParseHexColor(t *testing.T) {
+	for _, s := range []string{"#ff8800", "#f80", " #ff8800cc\n", "FF8800"} {
+		if got, err := ParseHexColor(s); err != nil || got != TrueColor(0xff, 0x88, 0x00) {
+			t.Errorf("ParseHexColor(%q) = (%v, %v)", s, got, err)
+		}
+	}
+	for _, s := range []string{"", "#ff88", "#gg8800", "#+f8800"} {
+		if _, err := ParseHexColor(s); err == nil {
+			t.Errorf("expected an error for %q", s)
+		}
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected MustParseHexColor to panic for an invalid color")
+		}
+	}()
+	MustParseHexColor("orange")
+}
@@ -7,8 +7,6 @@ import (
 	"time"
 	"unicode"
 	"unicode/utf8"
-
-	"golang.org/x/sys/unix"
 )
 
 // KeyString reads a keypress and returns it as a string, without flushing pending input
@@ -20,7 +18,7 @@ func (tty *TTY) KeyString() string {
 
 	// Block until first byte arrives
 	tty.SetTimeout(0)
-	n, err := unix.Read(tty.fd, buf)
+	n, err := tty.readBytes(buf)
 	if n < 0 {
 		n = 0
 	}
@@ -33,7 +31,7 @@ func (tty *TTY) KeyString() string {
 	}
 
 	b := buf[0]
-	escTimeout := 50 * time.Millisecond
+	escTimeout := tty.latency.escapeTimeout()
 
 	// Non-ESC: single ASCII byte or multi-byte UTF-8
 	if b != 27 {
@@ -53,7 +51,7 @@ func (tty *TTY) KeyString() string {
 			one := make([]byte, 1)
 			for i := 1; i < expected; i++ {
 				tty.SetTimeout(escTimeout)
-				numRead, err := unix.Read(tty.fd, one)
+				numRead, err := tty.readBytes(one)
 				if numRead < 0 {
 					numRead = 0
 				}
@@ -79,34 +77,41 @@ func (tty *TTY) KeyString() string {
 	// ESC byte: collect the rest of the escape sequence one byte at a time
 	seq := []byte{b}
 
+	// gap is the largest time spent waiting for a byte of the sequence
+	var gap time.Duration
 	one := make([]byte, 1)
 	tty.SetTimeout(escTimeout)
-	n, err = unix.Read(tty.fd, one)
+	start := time.Now()
+	n, err = tty.readBytes(one)
 	if n < 0 {
 		n = 0
 	}
 	if err != nil || n == 0 {
 		return "c:27" // bare ESC
 	}
+	gap = time.Since(start)
 	seq = append(seq, one[0])
 
 	// CSI sequence: ESC [ ... finalByte (0x40-0x7E)
 	if one[0] == '[' {
 		for {
 			tty.SetTimeout(escTimeout)
-			n, err = unix.Read(tty.fd, one)
+			start = time.Now()
+			n, err = tty.readBytes(one)
 			if n < 0 {
 				n = 0
 			}
 			if err != nil || n == 0 {
 				break
 			}
+			gap = max(gap, time.Since(start))
 			seq = append(seq, one[0])
 			if one[0] >= 0x40 && one[0] <= 0x7E {
 				break
 			}
 		}
 	}
+	tty.latency.observe(gap)
 
 	// Match against the known lookup tables
 	switch len(seq) {
@@ -0,0 +1,130 @@
+package vt
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/xyproto/env/v2"
+	"golang.org/x/term"
+)
+
+// modes keeps track of the terminal modes that have been changed by this
+// package. The zero values correspond to the terminal defaults.
+var modes struct {
+	lineWrapOff    atomic.Bool
+	cursorHidden   atomic.Bool
+	bracketedPaste atomic.Bool
+	reverseScreen  atomic.Bool
+	appCursorKeys  atomic.Bool
+	mouse          atomic.Bool
+}
+
+// DebugInfo is a snapshot of the decisions this package has made for the
+// current session. It can be encoded as JSON, or printed with String.
+type DebugInfo struct {
+	Term           string `json:"term"`            // $TERM
+	ColorTerm      string `json:"colorterm"`       // $COLORTERM
+	Multiplexed    bool   `json:"multiplexed"`     // running under tmux, screen, dvtm or abduco
+	XtermLike      bool   `json:"xterm_like"`      // $TERM starts with "xterm"
+	ColorProfile   string `json:"color_profile"`   // "none", "16", "256" or "truecolor"
+	Initialized    bool   `json:"initialized"`     // Init or ForceInit is in effect
+	LinearOutput   bool   `json:"linear_output"`   // the canvas is presented as linear text
+	StdoutTerminal bool   `json:"stdout_terminal"` // stdout is a terminal, not a pipe or a file
+	LineWrap       bool   `json:"line_wrap"`
+	CursorVisible  bool   `json:"cursor_visible"`
+	BracketedPaste bool   `json:"bracketed_paste"`
+	ReverseScreen  bool   `json:"reverse_screen"`
+	AppCursorKeys  bool   `json:"application_cursor_keys"`
+	Mouse          bool   `json:"mouse"`
+	Width          uint   `json:"width"`
+	Height         uint   `json:"height"`
+	SizeSource     string `json:"size_source"` // "terminal", "COLS", "COLUMNS" or "default"
+
+	// The timeouts are only set if a TTY was given to DebugState
+	ReadTimeout           time.Duration `json:"read_timeout,omitempty"`
+	EscapeTimeout         time.Duration `json:"escape_timeout,omitempty"`
+	EscapeTimeoutAdaptive bool          `json:"escape_timeout_adaptive,omitempty"`
+}
+
+// colorProfile returns the color support that is assumed for the terminal
+func colorProfile() string {
+	switch {
+	case EnvNoColor:
+		return "none"
+	case HasTrueColor():
+		return "truecolor"
+	case Has256Colors():
+		return "256"
+	default:
+		return "16"
+	}
+}
+
+// DebugState reports the detected terminal, the color profile, the modes
+// that are in effect, the terminal size and the timeouts of tty, which may
+// be nil. Applications can log it at startup, to make bug reports easier
+// to act on.
+func DebugState(tty *TTY) DebugInfo {
+	info := DebugInfo{
+		Term:           env.Str("TERM"),
+		ColorTerm:      env.Str("COLORTERM"),
+		Multiplexed:    multiplexed,
+		XtermLike:      xtermLike,
+		ColorProfile:   colorProfile(),
+		Initialized:    Initialized(),
+		LinearOutput:   linearOutput.Load(),
+		StdoutTerminal: term.IsTerminal(int(os.Stdout.Fd())),
+		LineWrap:       !modes.lineWrapOff.Load(),
+		CursorVisible:  !modes.cursorHidden.Load(),
+		BracketedPaste: modes.bracketedPaste.Load(),
+		ReverseScreen:  modes.reverseScreen.Load(),
+		AppCursorKeys:  modes.appCursorKeys.Load(),
+		Mouse:          modes.mouse.Load(),
+	}
+	info.Width, info.Height, info.SizeSource = termSize()
+	if tty != nil {
+		stats := tty.InputLatencyStats()
+		info.ReadTimeout = tty.Timeout()
+		info.EscapeTimeout = stats.EscapeTimeout
+		info.EscapeTimeoutAdaptive = stats.Adaptive
+	}
+	return info
+}
+
+// String returns a readable report, suitable for pasting into bug reports
+func (info DebugInfo) String() string {
+	var sb strings.Builder
+	yesNo := func(b bool) string {
+		if b {
+			return "yes"
+		}
+		return "no"
+	}
+	fmt.Fprintf(&sb, "TERM:                   %s\n", info.Term)
+	fmt.Fprintf(&sb, "COLORTERM:              %s\n", info.ColorTerm)
+	fmt.Fprintf(&sb, "Multiplexed:            %s\n", yesNo(info.Multiplexed))
+	fmt.Fprintf(&sb, "Xterm-like:             %s\n", yesNo(info.XtermLike))
+	fmt.Fprintf(&sb, "Color profile:          %s\n", info.ColorProfile)
+	fmt.Fprintf(&sb, "Initialized:            %s\n", yesNo(info.Initialized))
+	fmt.Fprintf(&sb, "Linear output:          %s\n", yesNo(info.LinearOutput))
+	fmt.Fprintf(&sb, "Stdout is a terminal:   %s\n", yesNo(info.StdoutTerminal))
+	fmt.Fprintf(&sb, "Line wrap:              %s\n", yesNo(info.LineWrap))
+	fmt.Fprintf(&sb, "Cursor visible:         %s\n", yesNo(info.CursorVisible))
+	fmt.Fprintf(&sb, "Bracketed paste:        %s\n", yesNo(info.BracketedPaste))
+	fmt.Fprintf(&sb, "Reverse screen:         %s\n", yesNo(info.ReverseScreen))
+	fmt.Fprintf(&sb, "App cursor keys:        %s\n", yesNo(info.AppCursorKeys))
+	fmt.Fprintf(&sb, "Mouse reporting:        %s\n", yesNo(info.Mouse))
+	fmt.Fprintf(&sb, "Size:                   %dx%d (%s)\n", info.Width, info.Height, info.SizeSource)
+	if info.ReadTimeout > 0 {
+		adaptive := "pinned"
+		if info.EscapeTimeoutAdaptive {
+			adaptive = "adaptive"
+		}
+		fmt.Fprintf(&sb, "Read timeout:           %s\n", info.ReadTimeout)
+		fmt.Fprintf(&sb, "Escape timeout:         %s (%s)\n", info.EscapeTimeout, adaptive)
+	}
+	return sb.String()
+}
@@ -0,0 +1,54 @@
+package vt
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// debugLogger writes timestamped lines to a debug log
+type debugLogger struct {
+	mut sync.Mutex
+	w   io.Writer
+}
+
+// printf writes a line to the log, prefixed with the current time
+func (l *debugLogger) printf(format string, args ...any) {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	fmt.Fprintf(l.w, time.Now().Format("15:04:05.000000")+" "+format+"\n", args...)
+}
+
+// SetDebugLog makes the TTY log every chunk of raw input bytes and every
+// decoded key to w, with timestamps. This is useful for finding out which
+// sequences a terminal sends. Pass nil to stop logging. Set the log before
+// reading from the TTY, not while another goroutine is reading.
+func (tty *TTY) SetDebugLog(w io.Writer) {
+	if w == nil {
+		tty.debugLog = nil
+		return
+	}
+	tty.debugLog = &debugLogger{w: w}
+}
+
+// logRaw logs raw input bytes, if a debug log is set
+func (tty *TTY) logRaw(b []byte) {
+	if tty.debugLog != nil && len(b) > 0 {
+		tty.debugLog.printf("raw   %q (% x)", b, b)
+	}
+}
+
+// logKey logs a decoded key, if a debug log is set
+func (tty *TTY) logKey(key string) {
+	if tty.debugLog != nil {
+		tty.debugLog.printf("key   %q", key)
+	}
+}
+
+// logKeyCode logs a decoded ASCII value or key code, if a debug log is set
+func (tty *TTY) logKeyCode(ascii, keyCode int, err error) {
+	if tty.debugLog != nil && (ascii != 0 || keyCode != 0 || err != nil) {
+		tty.debugLog.printf("code  ascii %d, key code %d, error %v", ascii, keyCode, err)
+	}
+}
@@ -0,0 +1,95 @@
+package vt
+
+// DiffStyle holds the colors used by RenderDiff
+type DiffStyle struct {
+	UnchangedFg, UnchangedBg AttributeColor // cells that are equal in a and b
+	OnlyAFg, OnlyABg         AttributeColor // cells that are only set in a
+	OnlyBFg, OnlyBBg         AttributeColor // cells that are only set in b
+	ChangedFg, ChangedBg     AttributeColor // cells that are set in both, but differ
+}
+
+// DefaultDiffStyle dims unchanged cells, tints cells that are only in a red
+// and cells that are only in b green, and highlights changed cells
+var DefaultDiffStyle = DiffStyle{
+	UnchangedFg: DarkGray,
+	UnchangedBg: DefaultBackground,
+	OnlyAFg:     LightRed,
+	OnlyABg:     TrueBackground(64, 0, 0),
+	OnlyBFg:     LightGreen,
+	OnlyBBg:     TrueBackground(0, 64, 0),
+	ChangedFg:   Black,
+	ChangedBg:   BackgroundYellow,
+}
+
+// DiffSummary holds the number of cells in each category found by RenderDiff
+type DiffSummary struct {
+	Unchanged int
+	OnlyA     int
+	OnlyB     int
+	Changed   int
+}
+
+// Differs reports whether any cells differ
+func (s DiffSummary) Differs() bool {
+	return s.OnlyA+s.OnlyB+s.Changed > 0
+}
+
+// snapshotCells returns a copy of the cells of c, and its size
+func (c *Canvas) snapshotCells() ([]ColorRune, uint, uint) {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	return append([]ColorRune(nil), c.chars...), c.w, c.h
+}
+
+// RenderDiff paints the differences between a and b onto dst, cell by cell.
+// Unchanged cells are drawn dimmed, cells that are only set in a (the rune
+// is 0 in b) are drawn red-tinted, cells that are only set in b are drawn
+// green-tinted and cells that are set in both but differ are highlighted,
+// using the colors in style. A zero style uses DefaultDiffStyle. The
+// compared area covers both a and b and is clipped to dst, where cells
+// outside of a smaller canvas count as empty. The returned summary counts
+// the cells in each category.
+func RenderDiff(dst, a, b *Canvas, style DiffStyle) DiffSummary {
+	if style == (DiffStyle{}) {
+		style = DefaultDiffStyle
+	}
+	achars, aw, ah := a.snapshotCells()
+	bchars, bw, bh := b.snapshotCells()
+	at := func(chars []ColorRune, cw, ch, x, y uint) ColorRune {
+		if x >= cw || y >= ch {
+			return ColorRune{}
+		}
+		return chars[y*cw+x]
+	}
+
+	dst.mut.Lock()
+	defer dst.mut.Unlock()
+	w := umin(max(aw, bw), dst.w)
+	h := umin(max(ah, bh), dst.h)
+	var summary DiffSummary
+	for y := range h {
+		for x := range w {
+			ca := at(achars, aw, ah, x, y)
+			cb := at(bchars, bw, bh, x, y)
+			cell := cb
+			switch {
+			case ca.r == cb.r && ca.fg == cb.fg && ca.bg == cb.bg && ca.cw == cb.cw && ca.attr == cb.attr:
+				summary.Unchanged++
+				cell.fg, cell.bg = style.UnchangedFg, style.UnchangedBg
+			case cb.r == 0 && cb.cw == 0:
+				summary.OnlyA++
+				cell = ca
+				cell.fg, cell.bg = style.OnlyAFg, style.OnlyABg
+			case ca.r == 0 && ca.cw == 0:
+				summary.OnlyB++
+				cell.fg, cell.bg = style.OnlyBFg, style.OnlyBBg
+			default:
+				summary.Changed++
+				cell.fg, cell.bg = style.ChangedFg, style.ChangedBg
+			}
+			cell.drawn = false
+			dst.chars[y*dst.w+x] = cell
+		}
+	}
+	return summary
+}
@@ -0,0 +1,47 @@
+package vt
+
+import "testing"
+
+func TestRenderDiff(t *testing.T) {
+	a := NewCanvasWithSize(4, 1)
+	b := NewCanvasWithSize(5, 1)
+	a.WriteString(0, 0, Default, DefaultBackground, "abc")
+	b.WriteString(0, 0, Default, DefaultBackground, "ab")
+	b.WriteString(2, 0, Red, DefaultBackground, "x")
+	b.WriteString(4, 0, Default, DefaultBackground, "y")
+
+	dst := NewCanvasWithSize(5, 1)
+	got := RenderDiff(dst, a, b, DiffStyle{})
+	want := DiffSummary{Unchanged: 3, Changed: 1, OnlyB: 1}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	row := dst.Row(0)
+	for i, tc := range []struct {
+		r  rune
+		fg AttributeColor
+	}{
+		{'a', DefaultDiffStyle.UnchangedFg},
+		{'b', DefaultDiffStyle.UnchangedFg},
+		{'x', DefaultDiffStyle.ChangedFg},
+		{0, DefaultDiffStyle.UnchangedFg},
+		{'y', DefaultDiffStyle.OnlyBFg},
+	} {
+		if row[i].Rune() != tc.r || row[i].Fg() != tc.fg {
+			t.Errorf("cell %d: got (%q, %v), want (%q, %v)", i, row[i].Rune(), row[i].Fg(), tc.r, tc.fg)
+		}
+	}
+
+	if s := RenderDiff(dst, b, a, DiffStyle{}); s.OnlyA != 1 || !s.Differs() {
+		t.Errorf("got %+v with the canvases swapped", s)
+	}
+	if s := RenderDiff(a, a, a, DiffStyle{}); s.Differs() || s.Unchanged != 4 {
+		t.Errorf("got %+v when comparing a canvas with itself", s)
+	}
+
+	styled := a.Copy()
+	styled.WriteRuneStyled(1, 0, Default, DefaultBackground, AttrBold, 'b')
+	if s := RenderDiff(dst, a, &styled, DiffStyle{}); s.Changed != 1 || s.Unchanged != 3 {
+		t.Errorf("got %+v when only the text attributes differ", s)
+	}
+}
@@ -0,0 +1,68 @@
+package vt
+
+// dimAmount is how much true color and 256-color values are darkened by DimRegion
+const dimAmount = 0.5
+
+// dimColor returns a dimmed variant of a foreground or background color.
+// 256-color and true color values are darkened, while the Dim attribute is
+// used for the 16 standard foreground colors. Standard background colors
+// are left as they are, since the Dim attribute only affects the text.
+func dimColor(ac AttributeColor, background bool) AttributeColor {
+	if IsTrueColor(ac) || Is256Color(ac) {
+		return Darken(ac, dimAmount)
+	}
+	if background {
+		return ac
+	}
+	return ac.Combine(Dim)
+}
+
+// DimRegion dims the cells in the given region, for instance behind a modal
+// dialog. The original cells are remembered, so that UndimAll can restore
+// them exactly. Cells that are already dimmed are left as they are.
+func (c *Canvas) DimRegion(x, y, w, h uint) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if x >= c.w || y >= c.h {
+		return
+	}
+	w = umin(w, c.w-x)
+	h = umin(h, c.h-y)
+	if c.dimmed == nil {
+		c.dimmed = make(map[uint]ColorRune)
+	}
+	for cy := y; cy < y+h; cy++ {
+		for cx := x; cx < x+w; cx++ {
+			index := cy*c.w + cx
+			if _, ok := c.dimmed[index]; ok {
+				continue
+			}
+			cr := c.chars[index]
+			c.dimmed[index] = cr
+			cr.fg = dimColor(cr.fg, false)
+			cr.bg = dimColor(cr.bg, true)
+			cr.drawn = false
+			c.chars[index] = cr
+		}
+	}
+}
+
+// DimAll dims the entire canvas. See DimRegion.
+func (c *Canvas) DimAll() {
+	c.DimRegion(0, 0, c.W(), c.H())
+}
+
+// UndimAll restores all dimmed cells to what they were before they were
+// dimmed. Anything drawn on top of the dimmed cells in the meantime, such
+// as a dialog, is replaced by the original contents.
+func (c *Canvas) UndimAll() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	for index, cr := range c.dimmed {
+		if index < uint(len(c.chars)) {
+			cr.drawn = false
+			c.chars[index] = cr
+		}
+	}
+	c.dimmed = nil
+}
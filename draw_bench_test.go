@@ -0,0 +1,125 @@
+package vt
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+// captureStdout redirects os.Stdout to a temporary file. The returned
+// function restores os.Stdout and returns the number of bytes written.
+func captureStdout(tb testing.TB) func() int64 {
+	f, err := os.CreateTemp(tb.TempDir(), "stdout")
+	if err != nil {
+		tb.Skip(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = f
+	return func() int64 {
+		os.Stdout = stdout
+		defer f.Close()
+		fi, err := f.Stat()
+		if err != nil {
+			return 0
+		}
+		return fi.Size()
+	}
+}
+
+// drawWorkload prepares the canvas for the next frame
+type drawWorkload func(c *Canvas, frame int)
+
+// drawWorkloads are the realistic workloads exercised by BenchmarkDrawWorkloads
+var drawWorkloads = []struct {
+	name  string
+	setup func(c *Canvas)
+	frame drawWorkload
+}{
+	{"FirstDraw", fillPlain, func(c *Canvas, _ int) {
+		c.oldchars = nil
+	}},
+	{"SteadyState", fillPlain, func(*Canvas, int) {}},
+	{"SingleCell", fillPlain, func(c *Canvas, frame int) {
+		c.WriteRune(uint(frame)%c.w, uint(frame/int(c.w))%c.h, Red, Default, rune('a'+frame%26))
+	}},
+	{"ScrollRow", fillPlain, func(c *Canvas, _ int) {
+		c.Lock()
+		first := make([]ColorRune, c.w)
+		copy(first, c.chars[:c.w])
+		copy(c.chars, c.chars[c.w:])
+		copy(c.chars[len(c.chars)-int(c.w):], first)
+		c.Unlock()
+	}},
+	{"Checkerboard", fillPlain, func(c *Canvas, frame int) {
+		c.Lock()
+		for i := range c.chars {
+			if (i+frame)%2 == 0 {
+				c.chars[i].fg, c.chars[i].bg = White, BackgroundBlack
+			} else {
+				c.chars[i].fg, c.chars[i].bg = Black, BackgroundWhite
+			}
+		}
+		c.Unlock()
+	}},
+	{"WriteStringBurst", fillPlain, func(c *Canvas, frame int) {
+		burst := make([]rune, 500)
+		for i := range burst {
+			burst[i] = rune('a' + (i+frame)%26)
+		}
+		c.WriteString(0, 0, Green, Default, string(burst))
+	}},
+}
+
+// fillPlain fills the canvas with a repeating pattern of letters
+func fillPlain(c *Canvas) {
+	for i := range c.chars {
+		c.chars[i] = ColorRune{fg: Default, bg: DefaultBackground, r: rune('a' + i%26)}
+	}
+}
+
+func BenchmarkDrawWorkloads(b *testing.B) {
+	for _, size := range [][2]uint{{80, 24}, {300, 90}} {
+		for _, wl := range drawWorkloads {
+			b.Run(fmt.Sprintf("%s/%dx%d", wl.name, size[0], size[1]), func(b *testing.B) {
+				c := NewCanvasWithSize(size[0], size[1])
+				wl.setup(c)
+				done := captureStdout(b)
+				c.Draw() // the first frame is always a full redraw
+				b.ReportAllocs()
+				b.ResetTimer()
+				for n := 0; n < b.N; n++ {
+					wl.frame(c, n)
+					c.Draw()
+				}
+				b.StopTimer()
+				b.ReportMetric(float64(done())/float64(b.N+1), "bytes/frame")
+			})
+		}
+	}
+}
+
+// Allocation guardrails for the draw pipeline. These are deliberately a bit
+// above the current numbers, so that they only fail on real regressions.
+const (
+	maxAllocsSteadyState = 0
+	maxAllocsSingleCell  = 8
+)
+
+func TestDrawAllocations(t *testing.T) {
+	defer discardStdout(t)()
+	c := NewCanvasWithSize(80, 24)
+	fillPlain(c)
+	c.Draw()
+	if allocs := testing.AllocsPerRun(100, func() { c.Draw() }); allocs > maxAllocsSteadyState {
+		t.Errorf("steady-state frame: %.1f allocations, want at most %d", allocs, maxAllocsSteadyState)
+	}
+	frame := 0
+	allocs := testing.AllocsPerRun(100, func() {
+		c.WriteRune(uint(frame)%c.w, 0, Red, Default, rune('a'+frame%26))
+		frame++
+		c.Draw()
+	})
+	if allocs > maxAllocsSingleCell {
+		t.Errorf("single-cell frame: %.1f allocations, want at most %d", allocs, maxAllocsSingleCell)
+	}
+}
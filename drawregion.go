@@ -0,0 +1,100 @@
+package vt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DrawRegion draws only the cells within the w x h rectangle at (x, y)
+// that changed since they were last drawn, clipped to the canvas. Each run
+// of changed cells on a row is written after positioning the cursor at its
+// start, and the rest of the terminal is left untouched. Returns true if
+// anything was written to the terminal.
+func (c *Canvas) DrawRegion(x, y, w, h uint) bool {
+	return c.drawRegion(x, y, w, h, false)
+}
+
+// RedrawRegion is like DrawRegion, but writes all the cells within the
+// rectangle, whether they changed or not
+func (c *Canvas) RedrawRegion(x, y, w, h uint) {
+	c.drawRegion(x, y, w, h, true)
+}
+
+// drawRegion draws the changed cells within the given rectangle, or all of
+// them if force is true
+func (c *Canvas) drawRegion(x, y, w, h uint, force bool) bool {
+	if linearOutput.Load() {
+		// Linear output is written one full row at a time
+		return c.draw(false)
+	}
+
+	c.mut.Lock()
+	x1, y1 := umin(x+w, c.w), umin(y+h, c.drawHeight())
+	if x >= x1 || y >= y1 {
+		c.mut.Unlock()
+		return false
+	}
+	// Without a previous frame, every cell in the region counts as changed.
+	// The previous frame is then left empty, so that the next Draw writes
+	// the rest of the canvas as well.
+	firstRun := len(c.oldchars) != len(c.chars)
+	force = force || firstRun
+	lastIdx := c.w*c.h - 1
+
+	var sb strings.Builder
+	sb.WriteString(beginSyncUpdate)
+	sb.WriteString(hideCursor)
+
+	written := false
+	var lastfg, lastbg AttributeColor
+	var lastattr TextAttr
+	termX, termY := c.termX, c.termY
+	for row := y; row < y1; row++ {
+		base := row * c.w
+		inRun := false
+		for col := x; col < x1; col++ {
+			idx := base + col
+			cr := (*c).chars[idx]
+			if cr.cw == 1 {
+				continue
+			}
+			if cr.drawn || (!force && !cr.needsDraw((*c).oldchars[idx])) {
+				inRun = false
+				continue
+			}
+			written = true
+			if idx == lastIdx {
+				c.writeLastCell(&sb, cr)
+				termX, termY = c.w, row
+				inRun = false
+				continue
+			}
+			if !inRun {
+				// Reset all attributes at the start of each run, as Draw
+				// does at the start of each line
+				fmt.Fprintf(&sb, "\033[%d;%dH\033[0m", row+1, col+1)
+				writeCellColors(&sb, cr)
+				inRun = true
+			} else if !lastfg.Equal(cr.fg) || !lastbg.Equal(cr.bg) || lastattr != cr.attr {
+				sb.WriteString(attrReset(lastattr))
+				writeCellColors(&sb, cr)
+			}
+			lastfg, lastbg, lastattr = cr.fg, cr.bg, cr.attr
+			if cr.r != 0 {
+				sb.WriteRune(cr.r)
+			} else {
+				sb.WriteByte(' ')
+			}
+			termX, termY = col+1+uint(cr.cw/2), row
+		}
+		if !firstRun {
+			updateOldChars(c.oldchars[base+x:base+x1], c.chars[base+x:base+x1])
+		}
+	}
+
+	if !written {
+		c.mut.Unlock()
+		return false
+	}
+	return c.finishDraw(&sb, c.out, false, c.cursorVisible, false, termX, termY, nil)
+}
@@ -0,0 +1,110 @@
+package vt
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// eventPollInterval is how often the goroutine started by Events checks if
+// its context has been cancelled, while waiting for input
+const eventPollInterval = 50 * time.Millisecond
+
+// Event is a single input event read from a TTY
+type Event struct {
+	Key       string      // the key, in the same format as returned by ReadKey
+	Rune      rune        // the typed character, if Key is a single printable rune
+	Modifiers int         // a combination of MouseShift, MouseAlt and MouseCtrl
+	Mouse     *MouseEvent // the decoded mouse report, if Key is one
+}
+
+// newEvent returns the event for a key, as returned by ReadKey
+func newEvent(key string) Event {
+	ev := Event{Key: key}
+	if mouse, ok := ParseMouseEvent(key); ok {
+		ev.Mouse = &mouse
+		ev.Modifiers = mouse.Modifiers
+		return ev
+	}
+	if r, size := utf8.DecodeRuneInString(key); size == len(key) && unicode.IsPrint(r) {
+		ev.Rune = r
+		return ev
+	}
+	ev.Modifiers = keyModifiers(key)
+	return ev
+}
+
+// keyModifiers returns the modifier bits for a key, as returned by ReadKey
+func keyModifiers(key string) int {
+	switch {
+	case strings.HasPrefix(key, "ctrl"):
+		return MouseCtrl
+	case strings.HasPrefix(key, "alt"):
+		return MouseAlt
+	case strings.HasPrefix(key, "shift"):
+		return MouseShift
+	}
+	// Ctrl-A to Ctrl-Z, except for Tab, Enter and Return, which are
+	// usually typed without Ctrl
+	if code, found := strings.CutPrefix(key, "c:"); found {
+		if n, err := strconv.Atoi(code); err == nil && n >= 1 && n <= 26 && n != 9 && n != 10 && n != 13 {
+			return MouseCtrl
+		}
+	}
+	return 0
+}
+
+// ReadEventTimeout waits up to d for input and returns the next event.
+// ok is false if no input arrived before the timeout, which makes it
+// possible to tell "no key" apart from a real key. This is handy for main
+// loops that advance an animation between key presses, without a separate
+// ticker. Mouse reports, enabled with EnableMouse, are decoded into Mouse.
+// A negative d waits indefinitely.
+func (tty *TTY) ReadEventTimeout(d time.Duration) (Event, bool) {
+	if !tty.HasPendingInput() {
+		ready, err := tty.Poll(d)
+		if err != nil || !ready {
+			return Event{}, false
+		}
+	}
+	key := tty.ReadKey()
+	if key == "" {
+		return Event{}, false
+	}
+	return newEvent(key), true
+}
+
+// Events starts a goroutine that reads input from the TTY and sends it on
+// the returned channel, so that input can be selected on together with
+// timers or other channels. Mouse reports, enabled with EnableMouse, arrive
+// on the same channel, with Mouse set. The goroutine stops and the channel
+// is closed when ctx is cancelled. The TTY should not be read from by
+// anything else while the goroutine is running.
+func (tty *TTY) Events(ctx context.Context) <-chan Event {
+	events := make(chan Event, 64)
+	go func() {
+		defer close(events)
+		for ctx.Err() == nil {
+			start := time.Now()
+			ev, ok := tty.ReadEventTimeout(eventPollInterval)
+			if !ok {
+				// Avoid spinning when the input ends or reports errors
+				if wait := eventPollInterval - time.Since(start); wait > 0 {
+					select {
+					case <-ctx.Done():
+					case <-time.After(wait):
+					}
+				}
+				continue
+			}
+			select {
+			case events <- ev:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return events
+}
@@ -0,0 +1,152 @@
+package vt
+
+import "fmt"
+
+// GraphNode is a node for DrawNetworkGraph. X and Y are normalized
+// coordinates in the range 0.0 to 1.0, relative to the drawing region.
+type GraphNode struct {
+	ID    string
+	Label string
+	X, Y  float64
+	Color AttributeColor
+}
+
+// GraphEdge connects the nodes with the IDs From and To
+type GraphEdge struct {
+	From, To string
+	Label    string
+	Color    AttributeColor
+}
+
+// GraphOpts holds options for DrawNetworkGraph
+type GraphOpts struct {
+	Style      BoxStyle       // border style for the nodes, BoxStyleSingle if not set
+	Background AttributeColor // background color for nodes, edges and labels
+}
+
+// GraphWarning describes a problem found while drawing a graph, such as
+// two nodes that overlap or an edge that refers to an unknown node
+type GraphWarning struct {
+	NodeID  string
+	OtherID string
+	Message string
+}
+
+// graphBox is the placement of a node within the drawing region
+type graphBox struct {
+	x, y, w, h uint
+}
+
+// center returns the center cell of the box
+func (b graphBox) center() (uint, uint) {
+	return b.x + b.w/2, b.y + b.h/2
+}
+
+// overlaps reports whether two boxes share at least one cell
+func (b graphBox) overlaps(o graphBox) bool {
+	return b.x < o.x+o.w && o.x < b.x+b.w && b.y < o.y+o.h && o.y < b.y+b.h
+}
+
+// DrawNetworkGraph draws a network topology diagram within the w x h region
+// at (x, y). Each node is drawn as a bordered label, centered at its scaled
+// coordinates, and edges are drawn as connection lines between node centers,
+// with the edge label placed at the midpoint. Nodes that overlap, and edges
+// that refer to unknown nodes, are returned as warnings.
+func (c *Canvas) DrawNetworkGraph(x, y, w, h uint, nodes []GraphNode, edges []GraphEdge, opts GraphOpts) []GraphWarning {
+	var warnings []GraphWarning
+	if w == 0 || h == 0 {
+		return warnings
+	}
+	bg := opts.Background
+
+	// Place the nodes
+	boxes := make(map[string]graphBox, len(nodes))
+	for _, n := range nodes {
+		bw := min(uint(len([]rune(n.Label)))+2, w)
+		bh := min(uint(3), h)
+		cx := x + uint(clampUnit(n.X)*float64(w-1)+0.5)
+		cy := y + uint(clampUnit(n.Y)*float64(h-1)+0.5)
+		bx := max(x, min(cx-min(cx, bw/2), x+w-bw))
+		by := max(y, min(cy-min(cy, bh/2), y+h-bh))
+		b := graphBox{bx, by, bw, bh}
+		for _, other := range nodes {
+			if other.ID == n.ID {
+				break
+			}
+			if ob, ok := boxes[other.ID]; ok && b.overlaps(ob) {
+				warnings = append(warnings, GraphWarning{n.ID, other.ID, fmt.Sprintf("node %q overlaps node %q", n.ID, other.ID)})
+			}
+		}
+		boxes[n.ID] = b
+	}
+
+	// Draw the edges below the nodes
+	for _, e := range edges {
+		from, okFrom := boxes[e.From]
+		to, okTo := boxes[e.To]
+		if !okFrom || !okTo {
+			missing := e.From
+			if okFrom {
+				missing = e.To
+			}
+			warnings = append(warnings, GraphWarning{e.From, e.To, fmt.Sprintf("edge refers to unknown node %q", missing)})
+			continue
+		}
+		x1, y1 := from.center()
+		x2, y2 := to.center()
+		c.DrawConnectionLine(x1, y1, x2, y2, e.Color, bg)
+	}
+
+	// Draw the nodes
+	for _, n := range nodes {
+		b := boxes[n.ID]
+		c.DrawBox(b.x, b.y, b.w, b.h, opts.Style, n.Color, bg)
+		c.WriteString(b.x+1, b.y+b.h/2, n.Color, bg, n.Label)
+	}
+
+	// Draw the edge labels on top
+	for _, e := range edges {
+		from, okFrom := boxes[e.From]
+		to, okTo := boxes[e.To]
+		if !okFrom || !okTo || e.Label == "" {
+			continue
+		}
+		mx, my := connectionMidpoint(from, to)
+		lx := mx - min(mx, uint(len([]rune(e.Label)))/2)
+		c.WriteString(lx, my, e.Color, bg, e.Label)
+	}
+
+	return warnings
+}
+
+// connectionMidpoint returns the cell halfway along the elbow-shaped
+// connection line that DrawConnectionLine draws between two node centers
+func connectionMidpoint(from, to graphBox) (uint, uint) {
+	x1, y1 := from.center()
+	x2, y2 := to.center()
+	dx := max(x1, x2) - min(x1, x2)
+	dy := max(y1, y2) - min(y1, y2)
+	half := (dx + dy) / 2
+	if half <= dx {
+		if x1 < x2 {
+			return x1 + half, y1
+		}
+		return x1 - half, y1
+	}
+	half -= dx
+	if y1 < y2 {
+		return x2, y1 + half
+	}
+	return x2, y1 - half
+}
+
+// clampUnit clamps v to the range [0.0, 1.0]
+func clampUnit(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
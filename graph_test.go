@@ -0,0 +1,29 @@
+package vt
+
+import "testing"
+
+func TestDrawNetworkGraph(t *testing.T) {
+	c := NewCanvasWithSize(30, 10)
+	nodes := []GraphNode{
+		{ID: "a", Label: "web", X: 0, Y: 0},
+		{ID: "b", Label: "db", X: 1, Y: 1},
+		{ID: "c", Label: "cache", X: 0.02, Y: 0.05},
+	}
+	edges := []GraphEdge{
+		{From: "a", To: "b"},
+		{From: "a", To: "missing"},
+	}
+	warnings := c.DrawNetworkGraph(0, 0, 30, 10, nodes, edges, GraphOpts{})
+	if len(warnings) != 2 {
+		t.Fatalf("expected 2 warnings, got %d: %v", len(warnings), warnings)
+	}
+	if warnings[0].NodeID != "c" || warnings[0].OtherID != "a" {
+		t.Errorf("unexpected overlap warning: %+v", warnings[0])
+	}
+	if r, _ := c.At(0, 0); r != '┌' {
+		t.Errorf("expected a box corner at (0,0), got %q", r)
+	}
+	if r, _ := c.At(29, 9); r != '┘' {
+		t.Errorf("expected a box corner at (29,9), got %q", r)
+	}
+}
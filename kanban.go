@@ -0,0 +1,115 @@
+package vt
+
+import "strings"
+
+// KanbanCard is a single card on a kanban board
+type KanbanCard struct {
+	Title, Body string
+	Priority    int // shown as up to three '!' in front of the title
+	Tags        []string
+}
+
+// KanbanColumn is a column of cards on a kanban board
+type KanbanColumn struct {
+	Title            string
+	Cards            []KanbanCard
+	Width            uint // defaults to 24 if 0
+	TitleFg, TitleBg AttributeColor
+	CardFg, CardBg   AttributeColor
+}
+
+// KanbanOpts holds options for DrawKanban
+type KanbanOpts struct {
+	ColumnGap   uint     // number of columns between each board column
+	CardPadding uint     // number of blank columns on each side of the card text
+	Style       BoxStyle // border style, BoxStyleSingle if not set
+}
+
+// defaultKanbanColumnWidth is used for columns where Width is not set
+const defaultKanbanColumnWidth = 24
+
+// DrawKanban draws a kanban board at (x, y): the columns are drawn side by
+// side, each with its title in the top border and the cards stacked
+// vertically inside. Card titles are truncated and card bodies are
+// word-wrapped to fit the column. All columns get the same height.
+// Returns the total width and height of the board.
+func (c *Canvas) DrawKanban(x, y uint, columns []KanbanColumn, opts KanbanOpts) (uint, uint) {
+	if len(columns) == 0 {
+		return 0, 0
+	}
+
+	// Lay out the cards of each column, to find the board height
+	type cardLayout struct {
+		title string
+		lines []string
+		tags  string
+	}
+	layouts := make([][]cardLayout, len(columns))
+	totalH := uint(2)
+	for i, col := range columns {
+		inner := int(kanbanColumnWidth(col)) - 4 - 2*int(opts.CardPadding)
+		h := uint(2)
+		for _, card := range col.Cards {
+			title := strings.Repeat("!", min(max(card.Priority, 0), 3))
+			if title != "" {
+				title += " "
+			}
+			title += card.Title
+			l := cardLayout{title: title, lines: wrapText(card.Body, inner)}
+			if card.Body == "" {
+				l.lines = nil
+			}
+			if len(card.Tags) > 0 {
+				l.tags = truncate("["+strings.Join(card.Tags, "] [")+"]", inner)
+			}
+			layouts[i] = append(layouts[i], l)
+			h += kanbanCardHeight(len(l.lines), l.tags != "")
+		}
+		totalH = max(totalH, h)
+	}
+
+	// Draw the columns and cards
+	pos := x
+	for i, col := range columns {
+		w := kanbanColumnWidth(col)
+		c.fillRect(pos, y, w, totalH, col.TitleFg, col.TitleBg)
+		c.drawTitledFrame(pos, y, w, totalH, opts.Style, col.TitleFg, col.TitleBg, col.Title, col.TitleFg)
+		cy := y + 1
+		for _, l := range layouts[i] {
+			ch := kanbanCardHeight(len(l.lines), l.tags != "")
+			c.fillRect(pos+1, cy, w-2, ch, col.CardFg, col.CardBg)
+			c.drawTitledFrame(pos+1, cy, w-2, ch, opts.Style, col.CardFg, col.CardBg, l.title, col.CardFg)
+			tx := pos + 2 + opts.CardPadding
+			for j, line := range l.lines {
+				c.WriteString(tx, cy+1+uint(j), col.CardFg, col.CardBg, line)
+			}
+			if l.tags != "" {
+				c.WriteString(tx, cy+1+uint(len(l.lines)), col.CardFg, col.CardBg, l.tags)
+			}
+			cy += ch
+		}
+		pos += w
+		if i < len(columns)-1 {
+			pos += opts.ColumnGap
+		}
+	}
+	return pos - x, totalH
+}
+
+// kanbanColumnWidth returns the width of the column, or the default width
+func kanbanColumnWidth(col KanbanColumn) uint {
+	if col.Width == 0 {
+		return defaultKanbanColumnWidth
+	}
+	return max(col.Width, 6)
+}
+
+// kanbanCardHeight returns the height of a card with the given number of
+// body lines, including the borders and the optional tag line
+func kanbanCardHeight(bodyLines int, hasTags bool) uint {
+	h := uint(2 + bodyLines)
+	if hasTags {
+		h++
+	}
+	return h
+}
@@ -0,0 +1,106 @@
+package vt
+
+import "testing"
+
+func TestWrapText(t *testing.T) {
+	got := wrapText("the quick brown fox", 10)
+	want := []string{"the quick", "brown fox"}
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+	if got := wrapText("abcdefgh", 3); len(got) != 3 || got[2] != "gh" {
+		t.Errorf("long word: got %q", got)
+	}
+	if got := wrapText("日本語 ab", 5); len(got) != 2 || got[0] != "日本" || got[1] != "語 ab" {
+		t.Errorf("wide runes: got %q", got)
+	}
+}
+
+func TestRenderMarkup(t *testing.T) {
+	if EnvNoColor {
+		t.Skip("NO_COLOR is set")
+	}
+	red := Red.String()
+	got := wrapEscaped(red+"the quick"+NoColor+" brown fox", 10)
+	want := []string{red + "the quick" + NoColor, "brown fox"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	// Colors that span a line break are carried over to the next line
+	got = wrapEscaped(red+"the quick brown"+NoColor+" fox", 10)
+	want = []string{red + "the quick" + NoColor, red + "brown" + NoColor + " fox"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	for _, line := range RenderMarkup("<b>bold</b> and <blue>blue</blue> text, wrapped\nnext", 12) {
+		if w := VisibleWidth(line); w > 12 {
+			t.Errorf("%q is %d columns wide", line, w)
+		}
+	}
+	if got := RenderMarkup("<red>abcdefgh</red>", 3); len(got) != 3 || StripColors(got[2]) != "gh" {
+		t.Errorf("long word: got %q", got)
+	}
+}
+
+func TestWriteStringWrapped(t *testing.T) {
+	c := NewCanvasWithSize(8, 4)
+	if n := c.WriteStringWrapped(1, 0, 5, Default, DefaultBackground, "one two\nthree"); n != 3 {
+		t.Errorf("got %d lines, want 3", n)
+	}
+	if got, want := c.String(), " one    \n two    \n three  \n        \n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	// Stops at the bottom and at the right edge of the canvas
+	if n := c.WriteStringWrapped(5, 2, 10, Default, DefaultBackground, "abcdefghijk"); n != 2 {
+		t.Errorf("got %d lines, want 2", n)
+	}
+	if got, want := c.String(), " one    \n two    \n threabc\n     def\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	c := NewCanvasWithSize(10, 4)
+	s := "  indented paragraph\nnext"
+	if n := c.WriteText(0, 0, 10, 3, Default, DefaultBackground, s); n != 3 {
+		t.Errorf("got %d lines, want 3", n)
+	}
+	if got, want := c.String(), "  indented\nparagraph \nnext      \n          \n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if cols, rows := c.MeasureText(10, s); cols != 10 || rows != 3 {
+		t.Errorf("got %dx%d, want 10x3", cols, rows)
+	}
+	// Stops after maxH lines
+	c.Clear()
+	if n := c.WriteText(0, 0, 10, 2, Default, DefaultBackground, "a\nb\nc"); n != 2 {
+		t.Errorf("got %d lines, want 2", n)
+	}
+	if cols, rows := c.MeasureText(3, "  abcdef"); cols != 3 || rows != 3 {
+		t.Errorf("got %dx%d, want 3x3", cols, rows)
+	}
+}
+
+func TestDrawKanban(t *testing.T) {
+	c := NewCanvasWithSize(40, 10)
+	columns := []KanbanColumn{
+		{Title: "Todo", Width: 16, Cards: []KanbanCard{{Title: "Write docs", Body: "for the new widgets", Priority: 1, Tags: []string{"docs"}}}},
+		{Title: "Done", Width: 12},
+	}
+	w, h := c.DrawKanban(0, 0, columns, KanbanOpts{ColumnGap: 1})
+	if w != 29 {
+		t.Errorf("width: got %d, want 29", w)
+	}
+	// column borders + card borders + 2 body lines + tag line
+	if h != 7 {
+		t.Errorf("height: got %d, want 7", h)
+	}
+	if r, _ := c.At(17, 0); r != '┌' {
+		t.Errorf("expected the second column to start at x=17, got %q", r)
+	}
+}
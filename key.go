@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"sync/atomic"
 	"time"
 	"unicode"
 	"unicode/utf8"
@@ -36,16 +37,38 @@ type TTY struct {
 	// Restore, Flush, SetTimeout, Poll, Close, ...) become no-ops and byte
 	// reads go through readBytes instead of unix.Read.
 	reader io.Reader
+	// pasteOpts is applied to the text returned by ReadPasteData
+	pasteOpts PasteOptions
+	// onPaste, when set, receives bracketed pastes in chunks. See OnPaste.
+	onPaste func(chunk string)
+	// pasteErr is the error from the last paste that was streamed to
+	// onPaste, if any. See PasteError.
+	pasteErr error
+	// latency tracks how quickly escape sequences arrive, for adapting the
+	// escape timeout
+	latency latencyTracker
+	// lastActivity is when input last arrived, in Unix nanoseconds
+	lastActivity atomic.Int64
+	// closed is set by Close, so that closing twice is harmless
+	closed bool
+	// debugLog, when set, receives raw input bytes and decoded keys
+	debugLog *debugLogger
 }
 
-// readBytes is the single byte-read entry point used by ReadKey, Rune and
-// asciiAndKeyCode. When a mock reader has been installed via
+// readBytes is the single byte-read entry point used by ReadKey, Rune,
+// ReadString, KeyString and asciiAndKeyCode. When a mock reader has been installed via
 // NewTTYFromReader it is used instead of the terminal file descriptor.
-func (tty *TTY) readBytes(buf []byte) (int, error) {
+func (tty *TTY) readBytes(buf []byte) (n int, err error) {
 	if tty.reader != nil {
-		return tty.reader.Read(buf)
+		n, err = tty.reader.Read(buf)
+	} else {
+		n, err = unix.Read(tty.fd, buf)
+	}
+	if n > 0 {
+		tty.markActivity()
+		tty.logRaw(buf[:n])
 	}
-	return unix.Read(tty.fd, buf)
+	return n, err
 }
 
 // clamp restricts v to the range [lo, hi]
@@ -103,9 +126,19 @@ func tcsetattr(fd int, attr *unix.Termios) error {
 	return unix.IoctlSetTermios(fd, ioctlSETATTR, attr)
 }
 
-// NewTTY opens /dev/tty in raw+cbreak mode with a read timeout
+// ttyOrig is the terminal state from before the first TTY was opened
+var ttyOrig sharedState[unix.Termios]
+
+// NewTTY opens /dev/tty in raw+cbreak mode with a read timeout.
+// Several TTYs may be open at the same time. The terminal state from before
+// the first one was opened is restored when the last one is closed.
 func NewTTY() (*TTY, error) {
-	fd, err := unix.Open("/dev/tty", unix.O_NOCTTY|unix.O_CLOEXEC|unix.O_NDELAY|unix.O_RDWR, 0666)
+	return openTTY("/dev/tty")
+}
+
+// openTTY opens the terminal device at path in raw+cbreak mode
+func openTTY(path string) (*TTY, error) {
+	fd, err := unix.Open(path, unix.O_NOCTTY|unix.O_CLOEXEC|unix.O_NDELAY|unix.O_RDWR, 0666)
 	if err != nil {
 		return nil, err
 	}
@@ -142,7 +175,13 @@ func NewTTY() (*TTY, error) {
 		return nil, err
 	}
 
-	return &TTY{fd: fd, orig: orig, timeout: defaultTimeout}, nil
+	// If another TTY is already open, orig was captured after it changed
+	// the terminal, so use the state from before the first one instead
+	orig = ttyOrig.acquire(orig)
+
+	tty := &TTY{fd: fd, orig: orig, timeout: defaultTimeout}
+	trackTTY(tty)
+	return tty, nil
 }
 
 // SetTimeout sets the read timeout.
@@ -177,7 +216,14 @@ func (tty *TTY) SetTimeoutNoSave(d time.Duration) error {
 	return nil
 }
 
-// Close restores the terminal and closes the file descriptor
+// VTEnableError returns the error from enabling VT processing when the TTY
+// was opened. VT processing is always available on Unix, so this is nil.
+func (tty *TTY) VTEnableError() error {
+	return nil
+}
+
+// Close closes the file descriptor, and restores the terminal if this is the
+// last open TTY
 func (tty *TTY) Close() {
 	if tty.reader != nil {
 		if c, ok := tty.reader.(io.Closer); ok {
@@ -185,7 +231,14 @@ func (tty *TTY) Close() {
 		}
 		return
 	}
-	tty.Restore()
+	if tty.closed {
+		return
+	}
+	tty.closed = true
+	untrackTTY(tty)
+	if ttyOrig.release() {
+		tty.Restore()
+	}
 	unix.Close(tty.fd)
 }
 
@@ -239,6 +292,7 @@ func (tty *TTY) Poll(d time.Duration) (bool, error) {
 
 // asciiAndKeyCode processes input into an ASCII or key code
 func asciiAndKeyCode(tty *TTY) (ascii, keyCode int, err error) {
+	defer func() { tty.logKeyCode(ascii, keyCode, err) }()
 	bytes := make([]byte, 6)
 
 	// Set raw mode, cbreak, and timeout before each read
@@ -337,6 +391,13 @@ func (tty *TTY) Key() int {
 	return key
 }
 
+// DecodeKey decodes the first key in buf, using the same escape sequence
+// parser as ReadKey. It returns the key string and the number of bytes
+// consumed. A consumed count of 0 means buf holds an incomplete sequence.
+func DecodeKey(buf []byte) (string, int) {
+	return parseFirstKey(buf)
+}
+
 // parseFirstKey parses the first key sequence from buf and returns its string
 // representation plus the number of bytes consumed. When the buffer starts
 // with an incomplete sequence (e.g. only ESC), consumed == 0 signals the
@@ -433,6 +494,16 @@ func parseFirstKey(buf []byte) (string, int) {
 // successive calls via a pending byte buffer — this prevents queued arrow
 // escapes from leaking into the document as literal "^[[..." text.
 func (tty *TTY) ReadKey() string {
+	key := tty.readKey()
+	tty.logKey(key)
+	if key == KeyPasteStartString && tty.onPaste != nil {
+		tty.pasteErr = tty.ReadPasteStream(tty.onPaste)
+	}
+	return key
+}
+
+// readKey is the implementation of ReadKey
+func (tty *TTY) readKey() string {
 	// Try to return a key already sitting in the pending buffer first. This is
 	// done before touching the terminal: RawMode below performs two ioctl
 	// syscalls, and calling it once per key while draining a large burst of
@@ -477,16 +548,23 @@ func (tty *TTY) ReadKey() string {
 	// sequence (e.g. lone ESC or ESC [ without a terminator), do one short
 	// follow-up read to let the rest arrive before classifying.
 	if key, consumed := parseFirstKey(tty.pending); consumed > 0 {
+		if consumed > 1 && tty.pending[0] == 27 {
+			tty.latency.observe(0) // the whole sequence arrived at once
+		}
 		tty.pending = tty.pending[consumed:]
 		return key
 	}
 	// Incomplete: wait briefly for the tail of the escape sequence.
-	tty.SetTimeoutNoSave(defaultTimeout)
+	tty.SetTimeoutNoSave(tty.latency.escapeTimeout())
+	start := time.Now()
 	numRead2, _ := tty.readBytes(readBuf)
 	if numRead2 > 0 {
 		tty.pending = append(tty.pending, readBuf[:numRead2]...)
 	}
 	if key, consumed := parseFirstKey(tty.pending); consumed > 0 {
+		if consumed > 1 {
+			tty.latency.observe(time.Since(start))
+		}
 		tty.pending = tty.pending[consumed:]
 		return key
 	}
@@ -638,7 +716,7 @@ func (tty *TTY) ReadString() (string, error) {
 	}
 
 	for {
-		n, err := unix.Read(tty.fd, buf)
+		n, err := tty.readBytes(buf)
 		if n < 0 {
 			n = 0
 		}
@@ -670,7 +748,7 @@ func (tty *TTY) ReadStringKeepTiming() (string, error) {
 	defer tty.SetTimeout(savedTimeout)
 
 	for {
-		n, err := unix.Read(tty.fd, buf)
+		n, err := tty.readBytes(buf)
 		if n < 0 {
 			n = 0
 		}
@@ -697,7 +775,7 @@ func (tty *TTY) PrintRawBytes() {
 		defer tty.SetTimeout(savedTimeout)
 	}
 
-	numRead, err := unix.Read(tty.fd, bytes)
+	numRead, err := tty.readBytes(bytes)
 	if numRead < 0 {
 		numRead = 0
 	}
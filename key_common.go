@@ -82,6 +82,12 @@ var keyCodeLookup = map[[3]byte]int{
 	{27, 91, 68}:  KeyLeft,     // Left Arrow
 	{27, 91, 'H'}: 1,           // Home (mapped to Ctrl-A)
 	{27, 91, 'F'}: 5,           // End (mapped to Ctrl-E)
+	{27, 79, 65}:  KeyUp,       // Up Arrow (SS3, application cursor keys)
+	{27, 79, 66}:  KeyDown,     // Down Arrow (SS3)
+	{27, 79, 67}:  KeyRight,    // Right Arrow (SS3)
+	{27, 79, 68}:  KeyLeft,     // Left Arrow (SS3)
+	{27, 79, 'H'}: 1,           // Home (SS3)
+	{27, 79, 'F'}: 5,           // End (SS3)
 	{27, 91, 90}:  KeyShiftTab, // Shift-Tab / Backtab (ESC [Z)
 	{27, 79, 80}:  KeyF1,       // F1  (ESC O P)
 	{27, 79, 81}:  KeyF2,       // F2  (ESC O Q)
@@ -147,6 +153,10 @@ var keyStringLookup = map[[3]byte]string{
 	{27, 91, 66}:  "↓",       // Down Arrow
 	{27, 91, 67}:  "→",       // Right Arrow
 	{27, 91, 68}:  "←",       // Left Arrow
+	{27, 79, 65}:  "↑",       // Up Arrow (SS3, application cursor keys)
+	{27, 79, 66}:  "↓",       // Down Arrow (SS3)
+	{27, 79, 67}:  "→",       // Right Arrow (SS3)
+	{27, 79, 68}:  "←",       // Left Arrow (SS3)
 	{27, 91, 'H'}: "⇱",       // Home
 	{27, 91, 'F'}: "⇲",       // End
 	{27, 79, 'H'}: "⇱",       // Home (SS3 sequence)
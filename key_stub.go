@@ -5,6 +5,7 @@ package vt
 import (
 	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 )
 
@@ -27,7 +28,15 @@ func (s *StubTerm) Read(p []byte) (int, error) {
 
 // TTY represents a terminal device
 type TTY struct {
-	timeout time.Duration
+	timeout   time.Duration
+	pasteOpts PasteOptions
+	onPaste   func(chunk string)
+	pasteErr  error
+	latency   latencyTracker
+	// lastActivity is when input last arrived, in Unix nanoseconds
+	lastActivity atomic.Int64
+	// debugLog is unused on this platform
+	debugLog *debugLogger
 }
 
 // NewTTY opens the terminal in raw mode (stub for unsupported platforms)
@@ -43,6 +52,14 @@ func (tty *TTY) SetTimeout(d time.Duration) (time.Duration, error) {
 	return saved, nil
 }
 
+// ReadPasteData reads the rest of a bracketed paste (stub)
+func (tty *TTY) ReadPasteData() (string, error) {
+	return "", errors.New("TTY is not supported on this platform")
+}
+
+// VTEnableError returns the error from enabling VT processing (stub: always nil)
+func (tty *TTY) VTEnableError() error { return nil }
+
 // Close will restore and close the raw terminal
 func (tty *TTY) Close() {}
 
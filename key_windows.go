@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/exec"
 	"strconv"
+	"sync/atomic"
 	"time"
 	"unicode"
 	"unsafe"
@@ -31,8 +32,48 @@ type TTY struct {
 	pending         []byte
 	escArmed        bool
 	reader          io.Reader
+	// inHandle/outHandle and their original console modes are saved by
+	// NewTTY so Close can put back the mode flags that were changed, in
+	// addition to restoring the term.State.
+	inHandle    windows.Handle
+	outHandle   windows.Handle
+	origInMode  uint32
+	origOutMode uint32
+	hasInMode   bool
+	hasOutMode  bool
+	// vtErr holds the error from enabling VT processing on the console
+	// output, if any. See VTEnableError.
+	vtErr error
+	// pasteOpts is applied to the text returned by ReadPasteData
+	pasteOpts PasteOptions
+	// onPaste, when set, receives bracketed pastes in chunks. See OnPaste.
+	onPaste func(chunk string)
+	// pasteErr is the error from the last paste that was streamed to
+	// onPaste, if any. See PasteError.
+	pasteErr error
+	// latency tracks how quickly escape sequences arrive. See
+	// InputLatencyStats.
+	latency latencyTracker
+	// lastActivity is when input last arrived, in Unix nanoseconds
+	lastActivity atomic.Int64
+	// closed is set by Close, so that closing twice is harmless
+	closed bool
+	// debugLog, when set, receives raw input bytes and decoded keys
+	debugLog *debugLogger
 }
 
+// consoleState is the console state that is saved when a TTY is opened
+type consoleState struct {
+	orig       *term.State
+	inMode     uint32
+	outMode    uint32
+	hasInMode  bool
+	hasOutMode bool
+}
+
+// ttyOrig is the console state from before the first TTY was opened
+var ttyOrig sharedState[consoleState]
+
 // NewTTY opens the terminal
 func NewTTY() (*TTY, error) {
 	fd := int(os.Stdin.Fd())
@@ -45,8 +86,22 @@ func NewTTY() (*TTY, error) {
 	var mode uint32
 	useConsoleInput := false
 	var orig *term.State
+	hasInMode := false
+
+	// Save the original output mode before enabling VT processing on it
+	var outMode uint32
+	outHandle, hasOutMode := consoleOutHandle()
+	var vtErr error
+	if hasOutMode {
+		if err := windows.GetConsoleMode(outHandle, &outMode); err != nil {
+			hasOutMode = false
+		} else {
+			vtErr = enableVT(outHandle)
+		}
+	}
 
 	if err := windows.GetConsoleMode(handle, &mode); err == nil {
+		hasInMode = true
 		// Real Windows console - prefer CONIN$ and use native KEY_EVENT decoding
 		if f, err := os.OpenFile("CONIN$", os.O_RDWR, 0); err == nil {
 			fd = int(f.Fd())
@@ -86,7 +141,12 @@ func NewTTY() (*TTY, error) {
 		}
 	}
 
-	return &TTY{
+	// If another TTY is already open, the state above was captured after it
+	// changed the console, so use the state from before the first one instead
+	st := ttyOrig.acquire(consoleState{orig, mode, outMode, hasInMode, hasOutMode})
+	orig, mode, outMode, hasInMode, hasOutMode = st.orig, st.inMode, st.outMode, st.hasInMode, st.hasOutMode
+
+	tty := &TTY{
 		fd:              fd,
 		orig:            orig,
 		timeout:         defaultTimeout,
@@ -94,7 +154,23 @@ func NewTTY() (*TTY, error) {
 		conin:           conin,
 		pending:         make([]byte, 0),
 		reader:          nil,
-	}, nil
+		inHandle:        handle,
+		outHandle:       outHandle,
+		origInMode:      mode,
+		origOutMode:     outMode,
+		hasInMode:       hasInMode,
+		hasOutMode:      hasOutMode,
+		vtErr:           vtErr,
+	}
+	trackTTY(tty)
+	return tty, nil
+}
+
+// VTEnableError returns the error from enabling VT processing on the console
+// output when the TTY was opened, or nil if it succeeded or was not needed.
+// No warning is printed, so callers can decide for themselves how to report it.
+func (tty *TTY) VTEnableError() error {
+	return tty.vtErr
 }
 
 // SetTimeout sets a timeout for reading a key.
@@ -105,9 +181,23 @@ func (tty *TTY) SetTimeout(d time.Duration) (time.Duration, error) {
 	return saved, nil
 }
 
-// Close restores the terminal
+// Close restores the terminal, including the original console input and
+// output mode flags, if this is the last open TTY
 func (tty *TTY) Close() {
-	tty.Restore()
+	if tty.closed {
+		return
+	}
+	tty.closed = true
+	untrackTTY(tty)
+	if tty.reader == nil && ttyOrig.release() {
+		tty.Restore()
+		if tty.hasInMode {
+			_ = windows.SetConsoleMode(tty.inHandle, tty.origInMode)
+		}
+		if tty.hasOutMode {
+			_ = windows.SetConsoleMode(tty.outHandle, tty.origOutMode)
+		}
+	}
 	if tty.conin != nil {
 		_ = tty.conin.Close()
 		tty.conin = nil
@@ -163,6 +253,7 @@ func (tty *TTY) Key() int {
 
 // asciiAndKeyCode processes input into an ASCII code or key code
 func asciiAndKeyCode(tty *TTY) (ascii, keyCode int, err error) {
+	defer func() { tty.logKeyCode(ascii, keyCode, err) }()
 	if tty.useConsoleInput {
 		return asciiAndKeyCodeConsole(tty)
 	}
@@ -291,6 +382,7 @@ func asciiAndKeyCodeConsole(tty *TTY) (ascii, keyCode int, err error) {
 		}
 
 		if ascii, keyCode = decodeConsoleKeyEvent(ke); ascii != 0 || keyCode != 0 {
+			tty.markActivity()
 			return ascii, keyCode, nil
 		}
 	}
@@ -380,12 +472,26 @@ func decodeConsoleKeyEvent(ke KEY_EVENT_RECORD) (ascii, keyCode int) {
 	return 0, 0
 }
 
+// readBytes reads input bytes, like readBytes on Unix
+func (tty *TTY) readBytes(buf []byte) (int, error) {
+	return tty.readWithTimeout(buf)
+}
+
 // readWithTimeout implements reading with timeout on Windows
-func (tty *TTY) readWithTimeout(b []byte) (int, error) {
-	if tty.useConsoleInput {
-		return tty.readWithTimeoutConsole(b)
+func (tty *TTY) readWithTimeout(b []byte) (n int, err error) {
+	switch {
+	case tty.reader != nil:
+		n, err = tty.reader.Read(b)
+	case tty.useConsoleInput:
+		n, err = tty.readWithTimeoutConsole(b)
+	default:
+		n, err = tty.readWithTimeoutPTY(b)
 	}
-	return tty.readWithTimeoutPTY(b)
+	if n > 0 {
+		tty.markActivity()
+		tty.logRaw(b[:n])
+	}
+	return n, err
 }
 
 // readWithTimeoutPTY reads from PTY (Git Bash) - simple ReadFile
@@ -505,6 +611,16 @@ func (tty *TTY) readWithTimeoutConsole(b []byte) (int, error) {
 
 // ReadKey reads a key sequence (or printable character) from the TTY.
 func (tty *TTY) ReadKey() string {
+	key := tty.readKey()
+	tty.logKey(key)
+	if key == KeyPasteStartString && tty.onPaste != nil {
+		tty.pasteErr = tty.ReadPasteStream(tty.onPaste)
+	}
+	return key
+}
+
+// readKey is the implementation of ReadKey
+func (tty *TTY) readKey() string {
 	bytes := make([]byte, 6)
 	tty.SetTimeout(0)
 	numRead, err := tty.readWithTimeout(bytes)
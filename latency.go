@@ -0,0 +1,113 @@
+package vt
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// latencyWindow is the number of escape sequences in the rolling window
+	latencyWindow = 32
+
+	// minEscapeTimeout and maxEscapeTimeout bound the adaptive escape timeout.
+	// On Unix, timeouts are rounded up to whole deciseconds by VTIME, so the
+	// effective minimum there is 100ms.
+	minEscapeTimeout = 25 * time.Millisecond
+	maxEscapeTimeout = time.Second
+)
+
+// LatencyStats holds statistics about how escape sequences arrived, over the
+// most recent sequences read by ReadKey and KeyString
+type LatencyStats struct {
+	Samples       int           // number of sequences in the window
+	Mean          time.Duration // mean of the largest gap between two bytes of a sequence
+	Max           time.Duration // largest gap between two bytes of a sequence
+	EscapeTimeout time.Duration // current time to wait for the rest of a sequence
+	Adaptive      bool          // false if the escape timeout is pinned
+}
+
+// latencyTracker keeps a rolling window of inter-byte gaps within escape
+// sequences, and derives the escape timeout from it
+type latencyTracker struct {
+	mut     sync.Mutex
+	samples [latencyWindow]time.Duration
+	n       int // number of samples, up to latencyWindow
+	next    int // index of the next sample to overwrite
+	pinned  time.Duration
+}
+
+// observe records the largest gap between two bytes of a sequence.
+// A sequence that arrived in one read has a gap of 0.
+func (lt *latencyTracker) observe(gap time.Duration) {
+	lt.mut.Lock()
+	defer lt.mut.Unlock()
+	lt.samples[lt.next] = gap
+	lt.next = (lt.next + 1) % latencyWindow
+	lt.n = min(lt.n+1, latencyWindow)
+}
+
+// maxGap returns the largest gap in the window. Must be called with mut held.
+func (lt *latencyTracker) maxGap() time.Duration {
+	var m time.Duration
+	for _, gap := range lt.samples[:lt.n] {
+		m = max(m, gap)
+	}
+	return m
+}
+
+// escapeTimeout returns the time to wait for the rest of an incomplete
+// escape sequence. Unless pinned, it is twice the largest recent gap,
+// within minEscapeTimeout and maxEscapeTimeout, or defaultTimeout if no
+// sequences have been seen yet.
+func (lt *latencyTracker) escapeTimeout() time.Duration {
+	lt.mut.Lock()
+	defer lt.mut.Unlock()
+	return lt.escapeTimeoutLocked()
+}
+
+func (lt *latencyTracker) escapeTimeoutLocked() time.Duration {
+	if lt.pinned > 0 {
+		return lt.pinned
+	}
+	if lt.n == 0 {
+		return defaultTimeout
+	}
+	return min(max(2*lt.maxGap(), minEscapeTimeout), maxEscapeTimeout)
+}
+
+// stats returns the current statistics
+func (lt *latencyTracker) stats() LatencyStats {
+	lt.mut.Lock()
+	defer lt.mut.Unlock()
+	s := LatencyStats{
+		Samples:       lt.n,
+		Max:           lt.maxGap(),
+		EscapeTimeout: lt.escapeTimeoutLocked(),
+		Adaptive:      lt.pinned == 0,
+	}
+	if lt.n > 0 {
+		var sum time.Duration
+		for _, gap := range lt.samples[:lt.n] {
+			sum += gap
+		}
+		s.Mean = sum / time.Duration(lt.n)
+	}
+	return s
+}
+
+// InputLatencyStats returns statistics about how quickly the bytes of
+// recent escape sequences arrived. On slow connections, such as laggy SSH
+// sessions, the gaps are larger.
+func (tty *TTY) InputLatencyStats() LatencyStats {
+	return tty.latency.stats()
+}
+
+// SetEscapeTimeout pins the time to wait for the rest of an incomplete
+// escape sequence, before treating a lone ESC as the Escape key. By
+// default, the timeout adapts to how quickly recent escape sequences
+// arrived. Pass 0 to go back to the adaptive timeout.
+func (tty *TTY) SetEscapeTimeout(d time.Duration) {
+	tty.latency.mut.Lock()
+	defer tty.latency.mut.Unlock()
+	tty.latency.pinned = max(d, 0)
+}
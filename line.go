@@ -0,0 +1,157 @@
+package vt
+
+// DrawConnectionLine connects (x1, y1) and (x2, y2) with an elbow-shaped
+// line of box drawing characters: first horizontally along row y1, then
+// vertically along column x2, with a corner where the two meet.
+func (c *Canvas) DrawConnectionLine(x1, y1, x2, y2 uint, fg, bg AttributeColor) {
+	// Horizontal part
+	lo, hi := min(x1, x2), max(x1, x2)
+	for x := lo; x <= hi; x++ {
+		c.WriteRune(x, y1, fg, bg, '─')
+	}
+	// Vertical part
+	lo, hi = min(y1, y2), max(y1, y2)
+	for y := lo; y <= hi; y++ {
+		c.WriteRune(x2, y, fg, bg, '│')
+	}
+	if x1 == x2 || y1 == y2 {
+		if y1 == y2 {
+			c.WriteRune(x2, y2, fg, bg, '─')
+		}
+		return
+	}
+	// Corner
+	var corner rune
+	switch {
+	case x1 < x2 && y1 < y2:
+		corner = '┐'
+	case x1 < x2:
+		corner = '┘'
+	case y1 < y2:
+		corner = '┌'
+	default:
+		corner = '└'
+	}
+	c.WriteRune(x2, y1, fg, bg, corner)
+}
+
+// HLine draws a horizontal line of length cells, starting at (x, y) and
+// going right. If r is 0, '─' is used. The line is clipped to the canvas.
+func (c *Canvas) HLine(x, y, length uint, fg, bg AttributeColor, r rune) {
+	if r == 0 {
+		r = '─'
+	}
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	px, py, ok := c.padded(x, y)
+	if !ok {
+		return
+	}
+	if length = umin(length, c.w-c.padRight-px); length > 0 {
+		c.WriteRunesBNoLock(px, py, fg, bg.Background(), r, length)
+	}
+}
+
+// VLine draws a vertical line of length cells, starting at (x, y) and going
+// down. If r is 0, '│' is used. The line is clipped to the canvas.
+func (c *Canvas) VLine(x, y, length uint, fg, bg AttributeColor, r rune) {
+	if r == 0 {
+		r = '│'
+	}
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	for j := y; j < y+length; j++ {
+		px, py, ok := c.padded(x, j)
+		if !ok {
+			return
+		}
+		c.WriteRuneBNoLock(px, py, fg, bg.Background(), r)
+	}
+}
+
+// Line draws a straight line from (x0, y0) to (x1, y1), both included,
+// using Bresenham's algorithm. If r is 0, each cell gets the box drawing
+// character that is closest to the direction of the line at that cell:
+// '─', '│', '╲' or '╱'. The line is clipped to the canvas.
+func (c *Canvas) Line(x0, y0, x1, y1 uint, fg, bg AttributeColor, r rune) {
+	var points [][2]int
+	ax, ay, bx, by := int(x0), int(y0), int(x1), int(y1)
+	dx, dy := abs(bx-ax), -abs(by-ay)
+	sx, sy := 1, 1
+	if ax > bx {
+		sx = -1
+	}
+	if ay > by {
+		sy = -1
+	}
+	for e := dx + dy; ; {
+		points = append(points, [2]int{ax, ay})
+		if ax == bx && ay == by {
+			break
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			ax += sx
+		}
+		if e2 <= dx {
+			e += dx
+			ay += sy
+		}
+	}
+	for i, p := range points {
+		cr := r
+		if cr == 0 {
+			// The direction of the step to the next point, or from the
+			// previous one for the last point
+			var from, to [2]int
+			switch {
+			case i+1 < len(points):
+				from, to = p, points[i+1]
+			case i > 0:
+				from, to = points[i-1], p
+			default:
+				from, to = p, p
+			}
+			cr = lineRune(to[0]-from[0], to[1]-from[1])
+		}
+		c.WriteRune(uint(p[0]), uint(p[1]), fg, bg, cr)
+	}
+}
+
+// lineRune returns the box drawing character for a step of (dx, dy)
+func lineRune(dx, dy int) rune {
+	switch {
+	case dy == 0:
+		return '─'
+	case dx == 0:
+		return '│'
+	case (dx > 0) == (dy > 0):
+		return '╲'
+	default:
+		return '╱'
+	}
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// DrawLine is an alias for Line
+func (c *Canvas) DrawLine(x0, y0, x1, y1 uint, fg, bg AttributeColor, r rune) {
+	c.Line(x0, y0, x1, y1, fg, bg, r)
+}
+
+// DrawHLine draws a horizontal line of '─' runes, like HLine
+func (c *Canvas) DrawHLine(x, y, length uint, fg, bg AttributeColor) {
+	c.HLine(x, y, length, fg, bg, 0)
+}
+
+// DrawVLine draws a vertical line of '│' runes, like VLine
+func (c *Canvas) DrawVLine(x, y, length uint, fg, bg AttributeColor) {
+	c.VLine(x, y, length, fg, bg, 0)
+}
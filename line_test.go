@@ -0,0 +1,45 @@
+package vt
+
+import "testing"
+
+func TestLine(t *testing.T) {
+	c := NewCanvasWithSize(5, 4)
+	c.Line(0, 0, 3, 3, Default, DefaultBackground, 0)
+	c.Line(4, 0, 4, 3, Default, DefaultBackground, 0)
+	c.Line(3, 0, 0, 3, Default, DefaultBackground, '*')
+	want := "╲  *│\n ╲* │\n *╲ │\n*  ╲│\n"
+	if got := c.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	c = NewCanvasWithSize(4, 3)
+	c.HLine(2, 0, 5, Default, DefaultBackground, 0)
+	c.VLine(0, 1, 5, Default, DefaultBackground, '#')
+	c.Line(0, 2, 9, 2, Default, DefaultBackground, 0) // clipped
+	if got, want := c.String(), "  ──\n#   \n────\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestHLineAndVLineWithPadding(t *testing.T) {
+	c := NewCanvasWithSize(5, 4)
+	c.SetPaddingArea(1, 1, 1, 1)
+	c.HLine(0, 0, 10, Red, Blue, 0)
+	c.VLine(0, 1, 10, Red, Blue, 0)
+	if got, want := c.String(), "     \n ─── \n │   \n     \n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if cr := c.chars[1*5+1]; cr.fg != Red || cr.bg != Blue.Background() {
+		t.Errorf("unexpected colors: %+v", cr)
+	}
+}
+
+func TestDrawLineOutOfBounds(t *testing.T) {
+	c := NewCanvasWithSize(3, 3)
+	c.DrawLine(1, 1, 100, 100, Default, DefaultBackground, '*')
+	c.DrawHLine(0, 0, 100, Default, DefaultBackground)
+	c.DrawVLine(0, 1, 100, Default, DefaultBackground)
+	if got, want := c.String(), "───\n│* \n│ *\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,76 @@
+package vt
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/xyproto/env/v2"
+)
+
+// linearOutput is true when the canvas should be presented as linear,
+// append-only text instead of with cursor-addressed redraws
+var linearOutput atomic.Bool
+
+func init() {
+	linearOutput.Store(env.Bool("ACCESSIBILITY"))
+}
+
+// SetLinearOutput enables or disables the linear output mode, which is
+// useful together with screen readers. In this mode, Canvas.Draw does not
+// position the cursor or emit colors; every changed row is instead printed
+// on a line of its own, as "line N: text". Init and Close also leave the
+// terminal alone. The mode is enabled at startup if ACCESSIBILITY is set.
+func SetLinearOutput(enable bool) {
+	linearOutput.Store(enable)
+}
+
+// LinearOutput returns true if the linear output mode is enabled
+func LinearOutput() bool {
+	return linearOutput.Load()
+}
+
+// linearFrame returns the rows that changed since the last frame as plain
+// text, one "line N: text" line per row, with trailing spaces trimmed.
+// When all is true, every row is included. It also updates oldchars.
+// The canvas mutex must be held.
+func (c *Canvas) linearFrame(all bool) string {
+	var sb strings.Builder
+	var line strings.Builder
+	w := c.w
+	for y := range c.h {
+		base := y * w
+		changed := all
+		for x := uint(0); x < w && !changed; x++ {
+			cr, oldcr := c.chars[base+x], c.oldchars[base+x]
+			changed = cr.r != oldcr.r
+		}
+		if !changed {
+			continue
+		}
+		line.Reset()
+		for x := range w {
+			cr := c.chars[base+x]
+			switch {
+			case cr.cw == 1:
+			case cr.r == 0:
+				line.WriteByte(' ')
+			default:
+				line.WriteRune(cr.r)
+			}
+		}
+		sb.WriteString("line ")
+		sb.WriteString(strconv.FormatUint(uint64(y+1), 10))
+		sb.WriteByte(':')
+		if text := strings.TrimRight(line.String(), " "); text != "" {
+			sb.WriteByte(' ')
+			sb.WriteString(text)
+		}
+		sb.WriteString("\r\n")
+	}
+	if lc := len(c.chars); len(c.oldchars) != lc {
+		c.oldchars = make([]ColorRune, lc)
+	}
+	copy(c.oldchars, c.chars)
+	return sb.String()
+}
@@ -0,0 +1,18 @@
+package vt
+
+import "testing"
+
+func TestLinearFrame(t *testing.T) {
+	c := NewCanvasWithSize(6, 3)
+	c.WriteString(0, 1, Red, Default, "hi")
+	if got, want := c.linearFrame(true), "line 1:\r\nline 2: hi\r\nline 3:\r\n"; got != want {
+		t.Errorf("first frame: got %q, want %q", got, want)
+	}
+	c.WriteString(0, 2, Red, Default, "there")
+	if got, want := c.linearFrame(false), "line 3: there\r\n"; got != want {
+		t.Errorf("second frame: got %q, want %q", got, want)
+	}
+	if got := c.linearFrame(false); got != "" {
+		t.Errorf("unchanged frame: got %q, want nothing", got)
+	}
+}
@@ -2,9 +2,12 @@ package vt
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 )
 
 func TestNewTTYFromReader_ReadsPrintableKeys(t *testing.T) {
@@ -120,3 +123,337 @@ func TestCanvasSnapshot_NonPrintableRendersAsQuestionMark(t *testing.T) {
 		t.Errorf("got:\n%q\nwant:\n%q", got, want)
 	}
 }
+
+func TestReadEventTimeout(t *testing.T) {
+	tty := NewTTYFromReader(strings.NewReader("q"))
+	ev, ok := tty.ReadEventTimeout(10 * time.Millisecond)
+	if !ok || ev.Key != "q" {
+		t.Errorf("got (%+v, %v), want ({Key:q}, true)", ev, ok)
+	}
+	if _, ok := tty.ReadEventTimeout(10 * time.Millisecond); ok {
+		t.Error("expected no event at the end of the input")
+	}
+}
+
+func TestTTYEvents(t *testing.T) {
+	tty := NewTTYFromReader(strings.NewReader("é\x1b[1;5A\x01\x1b[<16;3;2M"))
+	ctx, cancel := context.WithCancel(context.Background())
+	events := tty.Events(ctx)
+	want := []Event{
+		{Key: "é", Rune: 'é'},
+		{Key: "ctrl↑", Modifiers: MouseCtrl},
+		{Key: "c:1", Modifiers: MouseCtrl},
+		{Key: "\x1b[<16;3;2M", Modifiers: MouseCtrl},
+	}
+	for i, w := range want {
+		select {
+		case ev := <-events:
+			if ev.Key != w.Key || ev.Rune != w.Rune || ev.Modifiers != w.Modifiers || (ev.Mouse != nil) != (i == 3) {
+				t.Errorf("event %d: got %+v, want %+v", i, ev, w)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for event %d", i)
+		}
+	}
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("expected no more events")
+		}
+	case <-time.After(time.Second):
+		t.Error("expected the channel to be closed after the context is cancelled")
+	}
+}
+
+func TestNewTTYFromReader_ReplaysRecordedInput(t *testing.T) {
+	tty := NewTTYFromReader(strings.NewReader("é\x1b[Bq"))
+	if k := tty.KeyString(); k != "é" {
+		t.Errorf("KeyString: got %q, want %q", k, "é")
+	}
+	if k := tty.KeyString(); k != "↓" {
+		t.Errorf("KeyString: got %q, want the down arrow", k)
+	}
+	s, err := tty.ReadString()
+	if err != nil || s != "q" {
+		t.Errorf("ReadString: got (%q, %v), want (\"q\", nil)", s, err)
+	}
+	if _, err := tty.ReadString(); err == nil {
+		t.Error("ReadString: expected an error at the end of the input")
+	}
+}
+
+func TestDecodeKey(t *testing.T) {
+	for _, tc := range []struct {
+		in       string
+		want     string
+		consumed int
+	}{
+		{"a", "a", 1},
+		{"\x1b[A", "↑", 3},
+		{"\x1b[Axyz", "↑", 3},
+		{"\x03", "c:3", 1},
+		{"", "", 0},
+	} {
+		got, n := DecodeKey([]byte(tc.in))
+		if got != tc.want || n != tc.consumed {
+			t.Errorf("DecodeKey(%q) = (%q, %d), want (%q, %d)", tc.in, got, n, tc.want, tc.consumed)
+		}
+	}
+}
+
+func TestReadPasteData(t *testing.T) {
+	tty := NewTTYFromReader(strings.NewReader("\x1b[200~a\tb\r\nc\rd\x1b[201~x"))
+	if k := tty.ReadKey(); k != KeyPasteStartString {
+		t.Fatalf("got %q, want the paste start", k)
+	}
+	tty.SetPasteOptions(PasteOptions{NormalizeNewlines: true, TabWidth: 4})
+	s, err := tty.ReadPasteData()
+	if err != nil || s != "a   b\nc\nd" {
+		t.Errorf("got (%q, %v)", s, err)
+	}
+	if k := tty.ReadKey(); k != "x" {
+		t.Errorf("got %q after the paste, want %q", k, "x")
+	}
+
+	tty = NewTTYFromReader(strings.NewReader("\x1b[200~a\r\nb"))
+	tty.ReadKey()
+	if s, err := tty.ReadPasteData(); err == nil || s != "a\r\nb" {
+		t.Errorf("got (%q, %v), want the unmodified text and an error", s, err)
+	}
+}
+
+func TestOnPaste(t *testing.T) {
+	r := &delayedReader{
+		chunks: []string{"\x1b[200~", "a\tb\r", "\nc\xe6\x97", "\xa5d\x1b[20", "1~x"},
+		delays: make([]time.Duration, 5),
+	}
+	tty := NewTTYFromReader(r)
+	tty.SetPasteOptions(PasteOptions{NormalizeNewlines: true, TabWidth: 4})
+	var chunks []string
+	tty.OnPaste(func(chunk string) {
+		if !utf8.ValidString(chunk) {
+			t.Errorf("chunk %q is not valid UTF-8", chunk)
+		}
+		chunks = append(chunks, chunk)
+	})
+	if k := tty.ReadKey(); k != KeyPasteStartString {
+		t.Fatalf("got %q, want the paste start", k)
+	}
+	if len(chunks) < 2 {
+		t.Errorf("expected the paste in several chunks, got %q", chunks)
+	}
+	if s := strings.Join(chunks, ""); s != "a   b\nc日d" {
+		t.Errorf("got %q", s)
+	}
+	if err := tty.PasteError(); err != nil {
+		t.Errorf("unexpected paste error: %v", err)
+	}
+	if k := tty.ReadKey(); k != "x" {
+		t.Errorf("got %q after the paste, want %q", k, "x")
+	}
+
+	// A paste that is cut short is passed on, and the error is kept
+	tty = NewTTYFromReader(strings.NewReader("\x1b[200~abc"))
+	chunks = nil
+	tty.OnPaste(func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	tty.ReadKey()
+	if s := strings.Join(chunks, ""); s != "abc" {
+		t.Errorf("got %q from the cut short paste", s)
+	}
+	if tty.PasteError() == nil {
+		t.Error("expected an error for the cut short paste")
+	}
+}
+
+// delayedReader returns one chunk per Read, after sleeping for its delay
+type delayedReader struct {
+	chunks []string
+	delays []time.Duration
+}
+
+func (r *delayedReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+	time.Sleep(r.delays[0])
+	n := copy(p, r.chunks[0])
+	r.chunks, r.delays = r.chunks[1:], r.delays[1:]
+	return n, nil
+}
+
+func TestAdaptiveEscapeTimeout(t *testing.T) {
+	r := &delayedReader{
+		chunks: []string{"\x1b", "[A"},
+		delays: []time.Duration{0, 30 * time.Millisecond},
+	}
+	for range latencyWindow {
+		r.chunks = append(r.chunks, "\x1b[B")
+		r.delays = append(r.delays, 0)
+	}
+	tty := NewTTYFromReader(r)
+	if s := tty.InputLatencyStats(); s.Samples != 0 || s.EscapeTimeout != defaultTimeout || !s.Adaptive {
+		t.Errorf("unexpected initial stats: %+v", s)
+	}
+
+	if k := tty.ReadKey(); k != "↑" {
+		t.Fatalf("got %q, want the up arrow", k)
+	}
+	s := tty.InputLatencyStats()
+	if s.Samples != 1 || s.Max < 30*time.Millisecond || s.EscapeTimeout < 60*time.Millisecond {
+		t.Errorf("expected the timeout to widen after a slow sequence, got %+v", s)
+	}
+
+	for range latencyWindow {
+		if k := tty.ReadKey(); k != "↓" {
+			t.Fatalf("got %q, want the down arrow", k)
+		}
+	}
+	if s := tty.InputLatencyStats(); s.Max != 0 || s.EscapeTimeout != minEscapeTimeout {
+		t.Errorf("expected the timeout to narrow after instant sequences, got %+v", s)
+	}
+
+	tty.SetEscapeTimeout(200 * time.Millisecond)
+	if s := tty.InputLatencyStats(); s.EscapeTimeout != 200*time.Millisecond || s.Adaptive {
+		t.Errorf("expected a pinned timeout, got %+v", s)
+	}
+	tty.SetEscapeTimeout(0)
+	if s := tty.InputLatencyStats(); s.EscapeTimeout != minEscapeTimeout || !s.Adaptive {
+		t.Errorf("expected the adaptive timeout back, got %+v", s)
+	}
+}
+
+func TestIdleAndActivityHooks(t *testing.T) {
+	idleCh := make(chan struct{}, 4)
+	activeCh := make(chan struct{}, 4)
+	cancelIdle := OnIdle(20*time.Millisecond, func() { idleCh <- struct{}{} })
+	defer cancelIdle()
+	defer OnActivity(func() { activeCh <- struct{}{} })()
+
+	r := &delayedReader{
+		chunks: []string{"a", "b"},
+		delays: []time.Duration{0, 60 * time.Millisecond},
+	}
+	tty := NewTTYFromReader(r)
+	if !tty.LastActivity().IsZero() {
+		t.Error("expected no activity before reading")
+	}
+	if k := tty.ReadKey(); k != "a" {
+		t.Fatalf("got %q", k)
+	}
+	if tty.LastActivity().IsZero() {
+		t.Error("expected the activity time to be set")
+	}
+	select {
+	case <-activeCh:
+		t.Error("the activity hook should only fire after an idle period")
+	default:
+	}
+
+	// The idle hook fires while the reader is waiting for "b"
+	if k := tty.ReadKey(); k != "b" {
+		t.Fatalf("got %q", k)
+	}
+	select {
+	case <-idleCh:
+	case <-time.After(time.Second):
+		t.Fatal("the idle hook did not fire")
+	}
+	select {
+	case <-activeCh:
+	case <-time.After(time.Second):
+		t.Fatal("the activity hook did not fire after the idle period")
+	}
+}
+
+func TestDebugLog(t *testing.T) {
+	var log strings.Builder
+	tty := NewTTYFromReader(strings.NewReader("\x1b[Ax"))
+	tty.SetDebugLog(&log)
+	if key := tty.ReadKey(); key != "↑" {
+		t.Fatalf("got %q, want ↑", key)
+	}
+	got := log.String()
+	for _, want := range []string{`raw   "\x1b[Ax" (1b 5b 41 78)`, `key   "↑"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected the log to contain %q, got:\n%s", want, got)
+		}
+	}
+	tty.SetDebugLog(nil)
+	log.Reset()
+	tty.ReadKey()
+	if log.Len() != 0 {
+		t.Errorf("expected nothing to be logged after SetDebugLog(nil), got %q", log.String())
+	}
+}
+
+func TestPrompt(t *testing.T) {
+	var out strings.Builder
+	tty := NewTTYFromReader(strings.NewReader("x\x1b[AB"))
+	if got := prompt(tty, &out, "Continue? [a/b]", []rune{'a', 'b'}, 0); got != 'b' {
+		t.Errorf("got %q, want 'b'", got)
+	}
+	if got, want := out.String(), "Continue? [a/b] b\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	for input, want := range map[string]rune{"\r": 'n', "Y": 'y', "\x03": 0, "": 0} {
+		tty := NewTTYFromReader(strings.NewReader(input))
+		if got := prompt(tty, io.Discard, "Sure?", []rune{'y', 'n'}, 'n'); got != want {
+			t.Errorf("input %q: got %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestApplicationCursorKeys(t *testing.T) {
+	for seq, want := range map[string]string{
+		"\x1bOA": "↑", "\x1bOB": "↓", "\x1bOC": "→", "\x1bOD": "←", "\x1bOH": "⇱", "\x1bOF": "⇲",
+	} {
+		if key, n := DecodeKey([]byte(seq)); key != want || n != len(seq) {
+			t.Errorf("DecodeKey(%q): got (%q, %d), want (%q, %d)", seq, key, n, want, len(seq))
+		}
+	}
+	tty := NewTTYFromReader(strings.NewReader("\x1bOA"))
+	if key := tty.Key(); key != KeyUp {
+		t.Errorf("Key: got %d, want KeyUp", key)
+	}
+}
+
+func TestReadLine(t *testing.T) {
+	history := []string{"first", "second"}
+	for _, tc := range []struct {
+		input string
+		want  string
+		err   error
+	}{
+		{"hello\r", "hello", nil},
+		{"héllo\x7f\x7f\x7fy\r", "héy", nil},
+		{"ac\x1b[Db\x1b[H>\x1b[F<\r", ">abc<", nil},
+		{"abc\x1b[D\x1b[D\x1b[3~\r", "ac", nil},
+		{"\x1b[A\x1b[A\x1b[A!\r", "first!", nil},
+		{"new\x1b[A\x1b[B\r", "new", nil},
+		{"abc\x03", "", ErrInterrupted},
+		{"\x04", "", io.EOF},
+		{"abc", "abc", io.EOF},
+	} {
+		var out strings.Builder
+		tty := NewTTYFromReader(strings.NewReader(tc.input))
+		got, err := readLine(tty, &out, "> ", history, true)
+		if got != tc.want || err != tc.err {
+			t.Errorf("input %q: got (%q, %v), want (%q, %v)", tc.input, got, err, tc.want, tc.err)
+		}
+		if !strings.HasPrefix(out.String(), "> ") {
+			t.Errorf("expected the prompt to be written, got %q", out.String())
+		}
+	}
+
+	var out strings.Builder
+	tty := NewTTYFromReader(strings.NewReader("secret\r"))
+	if got, err := readLine(tty, &out, "Password: ", nil, false); got != "secret" || err != nil {
+		t.Errorf("got (%q, %v)", got, err)
+	}
+	if strings.Contains(out.String(), "secret") {
+		t.Errorf("expected the password not to be shown, got %q", out.String())
+	}
+}
@@ -0,0 +1,94 @@
+package vt
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	enableMouse  = "\033[?1000h\033[?1006h"
+	disableMouse = "\033[?1006l\033[?1000l"
+)
+
+// Mouse buttons, as found in MouseEvent.Button
+const (
+	MouseLeft      = 0
+	MouseMiddle    = 1
+	MouseRight     = 2
+	MouseWheelUp   = 64
+	MouseWheelDown = 65
+)
+
+// Modifier bits, as found in MouseEvent.Modifiers
+const (
+	MouseShift = 4
+	MouseAlt   = 8
+	MouseCtrl  = 16
+)
+
+// mouseMotion is set in the button code of an SGR mouse report when the
+// mouse moved while a button was held down
+const mouseMotion = 32
+
+// MouseEvent is a mouse report from the terminal, in the SGR (1006) format.
+// X and Y are 0-based, so that they can be passed directly to the Canvas
+// methods, while the terminal itself counts from 1.
+type MouseEvent struct {
+	Button    int  // MouseLeft, MouseMiddle, MouseRight, MouseWheelUp or MouseWheelDown
+	X, Y      uint // the 0-based cell that was clicked
+	Release   bool // the button was released, not pressed
+	Motion    bool // the mouse moved while the button was held down
+	Modifiers int  // a combination of MouseShift, MouseAlt and MouseCtrl
+}
+
+// ParseMouseEvent parses an SGR mouse report, like "\x1b[<0;10;5M", as
+// returned by ReadKey. ok is false if key is not a mouse report.
+func ParseMouseEvent(key string) (ev MouseEvent, ok bool) {
+	s, found := strings.CutPrefix(key, "\x1b[<")
+	if !found || len(s) < 6 {
+		return MouseEvent{}, false
+	}
+	final := s[len(s)-1]
+	if final != 'M' && final != 'm' {
+		return MouseEvent{}, false
+	}
+	fields := strings.Split(s[:len(s)-1], ";")
+	if len(fields) != 3 {
+		return MouseEvent{}, false
+	}
+	var nums [3]uint64
+	for i, field := range fields {
+		n, err := strconv.ParseUint(field, 10, 16)
+		if err != nil {
+			return MouseEvent{}, false
+		}
+		nums[i] = n
+	}
+	if nums[1] == 0 || nums[2] == 0 {
+		return MouseEvent{}, false
+	}
+	code := int(nums[0])
+	return MouseEvent{
+		Button:    code &^ (MouseShift | MouseAlt | MouseCtrl | mouseMotion),
+		X:         uint(nums[1] - 1),
+		Y:         uint(nums[2] - 1),
+		Release:   final == 'm',
+		Motion:    code&mouseMotion != 0,
+		Modifiers: code & (MouseShift | MouseAlt | MouseCtrl),
+	}, true
+}
+
+// EnableMouse makes the terminal report mouse clicks and wheel events in
+// the SGR format. The reports are returned by ReadKey as escape sequences,
+// which can be decoded with ParseMouseEvent, while ReadEventTimeout decodes
+// them as well.
+func (tty *TTY) EnableMouse() error {
+	modes.mouse.Store(true)
+	return tty.WriteString(enableMouse)
+}
+
+// DisableMouse stops the terminal from reporting mouse events
+func (tty *TTY) DisableMouse() error {
+	modes.mouse.Store(false)
+	return tty.WriteString(disableMouse)
+}
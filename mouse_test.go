@@ -0,0 +1,53 @@
+package vt
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMouseEvent(t *testing.T) {
+	for _, tc := range []struct {
+		key  string
+		want MouseEvent
+	}{
+		{"\x1b[<0;10;5M", MouseEvent{Button: MouseLeft, X: 9, Y: 4}},
+		{"\x1b[<2;1;1m", MouseEvent{Button: MouseRight, Release: true}},
+		{"\x1b[<65;3;7M", MouseEvent{Button: MouseWheelDown, X: 2, Y: 6}},
+		{"\x1b[<52;4;2M", MouseEvent{Button: MouseLeft, X: 3, Y: 1, Motion: true, Modifiers: MouseCtrl | MouseShift}},
+	} {
+		got, ok := ParseMouseEvent(tc.key)
+		if !ok || got != tc.want {
+			t.Errorf("ParseMouseEvent(%q) = (%+v, %v), want %+v", tc.key, got, ok, tc.want)
+		}
+	}
+	for _, key := range []string{"a", "\x1b[A", "\x1b[<0;0;1M", "\x1b[<0;1M", "\x1b[<0;x;1M", "\x1b[<0;1;1~"} {
+		if _, ok := ParseMouseEvent(key); ok {
+			t.Errorf("expected %q not to be parsed as a mouse event", key)
+		}
+	}
+}
+
+func TestReadEventMouse(t *testing.T) {
+	tty := NewTTYFromReader(strings.NewReader("\x1b[<0;10;5Mx\x1b[<0;10;5m"))
+	var events []Event
+	for {
+		ev, ok := tty.ReadEventTimeout(100 * time.Millisecond)
+		if !ok {
+			break
+		}
+		events = append(events, ev)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %+v", events)
+	}
+	if m := events[0].Mouse; m == nil || m.X != 9 || m.Y != 4 || m.Release {
+		t.Errorf("expected a mouse press at (9, 4), got %+v", m)
+	}
+	if events[1].Key != "x" || events[1].Mouse != nil {
+		t.Errorf("expected the key x, got %+v", events[1])
+	}
+	if m := events[2].Mouse; m == nil || !m.Release {
+		t.Errorf("expected a mouse release, got %+v", m)
+	}
+}
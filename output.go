@@ -0,0 +1,157 @@
+package vt
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SetOutput makes the canvas write all frames and escape sequences to w,
+// for instance a bytes.Buffer in tests, a pipe or a pty. Pass nil to write
+// to stdout again, which is the default. Since nothing is known about what
+// the new output shows, the next Draw writes the full frame, and the cursor
+// is assumed to be visible.
+func (c *Canvas) SetOutput(w io.Writer) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.out = w
+	c.oldchars = nil
+	c.termCursorVisible = true
+}
+
+// writeOutput writes s to out, or to stdout if out is nil
+func writeOutput(out io.Writer, s string) {
+	if out == nil {
+		writeAllToStdout([]byte(s))
+		return
+	}
+	io.WriteString(out, s)
+}
+
+// setXYOn moves the cursor of out, like SetXY
+func setXYOn(out io.Writer, x, y uint) {
+	writeOutput(out, fmt.Sprintf(cursorHomeTemplate, y+1, x+1))
+}
+
+// showCursorOn shows or hides the cursor of out, like ShowCursor
+func showCursorOn(out io.Writer, enable bool) {
+	if out == nil {
+		ShowCursor(enable)
+		return
+	}
+	if enable {
+		io.WriteString(out, showCursor)
+	} else {
+		io.WriteString(out, hideCursor)
+	}
+}
+
+// setLineWrapOn enables or disables line wrapping for out, like SetLineWrap
+func setLineWrapOn(out io.Writer, enable bool) {
+	if out == nil {
+		SetLineWrap(enable)
+		return
+	}
+	if enable {
+		io.WriteString(out, enableLineWrap)
+	} else {
+		io.WriteString(out, disableLineWrap)
+	}
+}
+
+// EachChangedCell calls fn for each cell that changed since the previous
+// frame, and then makes the current cells the previous frame, without
+// allocating. This can drive other displays than the terminal, by only
+// forwarding the changed cells. Continuation cells of wide runes are
+// skipped. Since the previous frame is shared with Draw, the two should not
+// be used together for the same canvas. fn is called while the canvas is
+// locked, so it must not call methods on the canvas.
+func (c *Canvas) EachChangedCell(fn func(x, y uint, cell ColorRune)) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	firstRun := len(c.oldchars) != len(c.chars)
+	for i, cr := range c.chars {
+		if cr.cw == 1 || cr.drawn || (!firstRun && !cr.needsDraw(c.oldchars[i])) {
+			continue
+		}
+		fn(uint(i)%c.w, uint(i)/c.w, cr)
+	}
+	if firstRun {
+		c.oldchars = make([]ColorRune, len(c.chars))
+	}
+	updateOldChars(c.oldchars, c.chars)
+}
+
+// ExportANSI returns the whole canvas as text with the escape sequences for
+// the colors and the text attributes of the cells, with a newline after
+// each row, for saving to a file that can be shown with cat, or for
+// writing to another terminal. As when drawing, a color is only emitted
+// when it changes. The colors are reset at the end of each row, so that
+// the background color does not spread when the terminal scrolls. Unlike
+// Draw, no cursor movement is included.
+func (c *Canvas) ExportANSI() string {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	return c.ansiString(true)
+}
+
+// ansiString returns the canvas as text with the escape sequences for the
+// colors and the text attributes, for ExportANSI and ColorString. If
+// resetEachRow is true, the colors are reset at the end of each row,
+// otherwise only at the very end. The canvas mutex must be held.
+func (c *Canvas) ansiString(resetEachRow bool) string {
+	var sb strings.Builder
+	sb.Grow(int(c.w*c.h*2 + c.h*8))
+	var lastfg, lastbg AttributeColor
+	var lastattr TextAttr
+	first := true
+	for y := range c.h {
+		for x := range c.w {
+			cr := c.chars[y*c.w+x]
+			if cr.cw == 1 {
+				continue
+			}
+			if first || !lastfg.Equal(cr.fg) || !lastbg.Equal(cr.bg) || lastattr != cr.attr {
+				if !first {
+					sb.WriteString(attrReset(lastattr))
+				}
+				writeCellColors(&sb, cr)
+				lastfg, lastbg, lastattr = cr.fg, cr.bg, cr.attr
+				first = false
+			}
+			if cr.r != 0 {
+				sb.WriteRune(cr.r)
+			} else {
+				sb.WriteByte(' ')
+			}
+		}
+		if resetEachRow {
+			if !EnvNoColor {
+				sb.WriteString(NoColor)
+			}
+			first = true
+		}
+		sb.WriteByte('\n')
+	}
+	if !resetEachRow && !EnvNoColor {
+		sb.WriteString(NoColor)
+	}
+	return sb.String()
+}
+
+// DrawStats describes the last frame that was drawn by Draw, for profiling
+type DrawStats struct {
+	CellsCompared int  // cells compared with the previous frame, 0 for the first frame
+	CellsChanged  int  // cells that were written because they changed
+	BytesWritten  int  // size of the frame, including the escape sequences
+	Skipped       bool // true if nothing had changed, so nothing was written
+}
+
+// LastDrawStats returns statistics about the last call to Draw, or to one
+// of the other methods that draw the whole canvas, like Redraw. This can be
+// used for finding out if a render loop forces more redrawing than needed.
+func (c *Canvas) LastDrawStats() DrawStats {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	return c.lastDrawStats
+}
@@ -0,0 +1,117 @@
+package vt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KeyPasteStartString is returned by ReadKey when the terminal starts a
+// bracketed paste. Call ReadPasteData to read the pasted text.
+const KeyPasteStartString = "\x1b[200~"
+
+// pasteEndString is sent by the terminal at the end of a bracketed paste
+const pasteEndString = "\x1b[201~"
+
+// SetBracketedPaste enables or disables bracketed paste. When enabled,
+// pasted text is surrounded by markers, so that it can be told apart from
+// typed keys.
+func SetBracketedPaste(enable bool) {
+	modes.bracketedPaste.Store(enable)
+	if enable {
+		fmt.Print(enablePaste)
+	} else {
+		fmt.Print(disablePaste)
+	}
+}
+
+// HasBracketedPaste returns true if the terminal is known to support
+// bracketed paste, which is when Capabilities.Apply was called with a probe
+// that found it
+func HasBracketedPaste() bool {
+	return probedBracketedPaste.Load()
+}
+
+// PasteOptions controls how ReadPasteData normalizes pasted text
+type PasteOptions struct {
+	// NormalizeNewlines converts CRLF and lone CR line endings to LF
+	NormalizeNewlines bool
+	// TabWidth, when larger than 0, expands tabs to spaces, up to the next
+	// multiple of TabWidth columns
+	TabWidth int
+}
+
+// SetPasteOptions sets the options that are applied by ReadPasteData
+func (tty *TTY) SetPasteOptions(opts PasteOptions) {
+	tty.pasteOpts = opts
+}
+
+// OnPaste sets a function that receives bracketed paste text as it arrives.
+// When set, ReadKey streams the whole paste to fn, chunk by chunk, when the
+// paste start marker is read, and then returns KeyPasteStartString. The
+// chunks are normalized according to the paste options. This lets very
+// large pastes be handled without holding all of the text in memory. If
+// the input ends before the paste does, the error is available from
+// PasteError. Pass nil to go back to reading pastes with ReadPasteData.
+func (tty *TTY) OnPaste(fn func(chunk string)) {
+	tty.onPaste = fn
+}
+
+// PasteError returns the error from the last paste that ReadKey streamed to
+// the function given to OnPaste, or nil if it was read up to the end marker
+func (tty *TTY) PasteError() error {
+	return tty.pasteErr
+}
+
+// pasteNormalizer applies the paste options to text that arrives in chunks,
+// keeping the tab column and a trailing CR from one chunk to the next
+type pasteNormalizer struct {
+	opts PasteOptions
+	col  int
+	cr   bool
+}
+
+// write returns the normalized text of the next chunk
+func (n *pasteNormalizer) write(s string) string {
+	if n.opts.NormalizeNewlines {
+		if n.cr {
+			s = "\r" + s
+			n.cr = false
+		}
+		// A CR at the end may be the first half of a CRLF
+		if strings.HasSuffix(s, "\r") {
+			s = s[:len(s)-1]
+			n.cr = true
+		}
+		s = strings.ReplaceAll(s, "\r\n", "\n")
+		s = strings.ReplaceAll(s, "\r", "\n")
+	}
+	if n.opts.TabWidth <= 0 {
+		return s
+	}
+	var sb strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\t':
+			spaces := n.opts.TabWidth - n.col%n.opts.TabWidth
+			sb.WriteString(strings.Repeat(" ", spaces))
+			n.col += spaces
+		case '\n', '\r':
+			sb.WriteRune(r)
+			n.col = 0
+		default:
+			sb.WriteRune(r)
+			n.col++
+		}
+	}
+	return sb.String()
+}
+
+// flush returns any text that was held back by write
+func (n *pasteNormalizer) flush() string {
+	if n.cr {
+		n.cr = false
+		n.col = 0
+		return "\n"
+	}
+	return ""
+}
@@ -0,0 +1,83 @@
+//go:build !plan9
+
+package vt
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// ReadPasteData reads the text of a bracketed paste, up to the end marker.
+// Call it after ReadKey has returned KeyPasteStartString. The text is
+// normalized according to the options given to SetPasteOptions. If the
+// input ends before the end marker, the text read so far is returned
+// together with an error.
+func (tty *TTY) ReadPasteData() (string, error) {
+	var sb strings.Builder
+	err := tty.ReadPasteStream(func(chunk string) {
+		sb.WriteString(chunk)
+	})
+	return sb.String(), err
+}
+
+// ReadPasteStream reads the text of a bracketed paste, up to the end marker,
+// and passes it to fn in chunks as it arrives. Call it after ReadKey has
+// returned KeyPasteStartString. A chunk never ends in the middle of a UTF-8
+// sequence, and the chunks are normalized according to the options given to
+// SetPasteOptions. If the input ends before the end marker, the text read so
+// far is passed on and an error is returned.
+func (tty *TTY) ReadPasteStream(fn func(chunk string)) error {
+	end := []byte(pasteEndString)
+	data := tty.pending
+	tty.pending = nil
+	norm := pasteNormalizer{opts: tty.pasteOpts}
+	emit := func(b []byte) {
+		if s := norm.write(string(b)); s != "" {
+			fn(s)
+		}
+	}
+
+	// Block until the end marker arrives
+	savedTimeout, err := tty.SetTimeout(0)
+	if err == nil {
+		defer tty.SetTimeout(savedTimeout)
+	}
+
+	buf := make([]byte, 4096)
+	for {
+		if i := bytes.Index(data, end); i >= 0 {
+			if rest := data[i+len(end):]; len(rest) > 0 {
+				tty.pending = append([]byte(nil), rest...)
+			}
+			emit(data[:i])
+			if s := norm.flush(); s != "" {
+				fn(s)
+			}
+			return nil
+		}
+		// Keep back what may be the start of the end marker or of a rune
+		cut := max(len(data)-(len(end)-1), 0)
+		for cut > 0 && !utf8.RuneStart(data[cut]) {
+			cut--
+		}
+		if cut > 0 {
+			emit(data[:cut])
+			data = append(data[:0], data[cut:]...)
+		}
+		n, err := tty.readBytes(buf)
+		if n > 0 {
+			data = append(data, buf[:n]...)
+			continue
+		}
+		if err == nil {
+			err = errors.New("no paste end marker")
+		}
+		emit(data)
+		if s := norm.flush(); s != "" {
+			fn(s)
+		}
+		return err
+	}
+}
@@ -16,5 +16,13 @@ func echoOffHelper() bool {
 	return true
 }
 
+// cleanupSignals are the signals that RegisterCleanup handles
+var cleanupSignals = []os.Signal{os.Interrupt}
+
+// signalExitCode returns the exit status after sig
+func signalExitCode(sig os.Signal) int {
+	return 1
+}
+
 // SetupResizeHandler is a no-op on Plan 9
 func SetupResizeHandler(sigChan chan os.Signal) {}
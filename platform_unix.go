@@ -6,12 +6,19 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+
+	"golang.org/x/term"
 )
 
 func initTerminal() {
 	// No-op on Unix
 }
 
+// stdoutIsTerminal returns true if stdout is a terminal
+func stdoutIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
 func showCursorHelper(enable bool) {
 	// No-op on Unix, handled by ANSI codes
 }
@@ -20,6 +27,17 @@ func echoOffHelper() bool {
 	return true
 }
 
+// cleanupSignals are the signals that RegisterCleanup handles
+var cleanupSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP}
+
+// signalExitCode returns the conventional exit status after sig
+func signalExitCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return 1
+}
+
 // SetupResizeHandler sets up a terminal resize signal handler
 func SetupResizeHandler(sigChan chan os.Signal) {
 	signal.Notify(sigChan, syscall.SIGWINCH)
@@ -4,9 +4,12 @@ package vt
 
 import (
 	"os"
+	"strings"
+	"syscall"
 	"unsafe"
 
 	"golang.org/x/sys/windows"
+	"golang.org/x/term"
 )
 
 func initTerminal() {
@@ -15,6 +18,29 @@ func initTerminal() {
 	}
 }
 
+// stdoutIsTerminal returns true if stdout is a console, or the pipe that
+// mintty and other Cygwin and MSYS2 terminals, like Git Bash, use as a PTY
+func stdoutIsTerminal() bool {
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		return true
+	}
+	handle := windows.Handle(os.Stdout.Fd())
+	if ft, err := windows.GetFileType(handle); err != nil || ft != windows.FILE_TYPE_PIPE {
+		return false
+	}
+	// The pipe is named like \msys-1888ae32e00d56aa-pty0-to-master
+	var info struct {
+		FileNameLength uint32
+		FileName       [windows.MAX_PATH]uint16
+	}
+	if err := windows.GetFileInformationByHandleEx(handle, windows.FileNameInfo, (*byte)(unsafe.Pointer(&info)), uint32(unsafe.Sizeof(info))); err != nil {
+		return false
+	}
+	n := min(int(info.FileNameLength/2), len(info.FileName))
+	name := windows.UTF16ToString(info.FileName[:n])
+	return (strings.Contains(name, "msys-") || strings.Contains(name, "cygwin-")) && strings.Contains(name, "-pty")
+}
+
 func consoleOutHandle() (windows.Handle, bool) {
 	handle, err := windows.GetStdHandle(windows.STD_OUTPUT_HANDLE)
 	if err != nil || handle == windows.InvalidHandle || handle == 0 {
@@ -74,6 +100,17 @@ func echoOffHelper() bool {
 	return false
 }
 
+// cleanupSignals are the signals that RegisterCleanup handles
+var cleanupSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// signalExitCode returns the conventional exit status after sig
+func signalExitCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return 1
+}
+
 // SetupResizeHandler is a no-op on Windows
 func SetupResizeHandler(sigChan chan os.Signal) {
 	// No-op on Windows
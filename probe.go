@@ -0,0 +1,183 @@
+package vt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/xyproto/env/v2"
+)
+
+// Terminal queries used by ProbeCapabilities
+const (
+	queryDA1           = "\033[c"
+	queryDA2           = "\033[>c"
+	queryKittyKeyboard = "\033[?u"
+	// Set a true-color foreground, then ask for the current SGR with DECRQSS
+	queryTrueColor = "\033[38;2;1;2;3m\033P$qm\033\\\033[0m"
+)
+
+// Capabilities holds the findings of ProbeCapabilities
+type Capabilities struct {
+	Term           string     // $TERM
+	ColorTerm      string     // $COLORTERM
+	Multiplexed    bool       // running under tmux, screen, dvtm or abduco
+	DA1            string     // raw Primary Device Attributes response
+	DA2            string     // raw Secondary Device Attributes response
+	HasBackground  bool       // the terminal answered the OSC 11 background color query
+	Background     [3]float64 // background color as normalized RGB, if HasBackground
+	TrueColor      bool       // the terminal reported back a 24-bit SGR
+	SyncUpdate     bool       // synchronized output (mode 2026) is supported
+	BracketedPaste bool       // bracketed paste (mode 2004) is supported
+	KittyKeyboard  bool       // the kitty keyboard protocol is supported
+}
+
+// Support that was found by ProbeCapabilities and applied with
+// Capabilities.Apply
+var (
+	probedTrueColor      atomic.Bool
+	probedBracketedPaste atomic.Bool
+)
+
+// ProbeCapabilities actively queries the terminal for what it supports.
+// Each query waits for a short while for a response, so this takes a
+// fraction of a second. Terminals that do not understand a query ignore it,
+// in which case the corresponding field is left empty or false.
+func ProbeCapabilities(tty *TTY) Capabilities {
+	caps := Capabilities{
+		Term:        env.Str("TERM"),
+		ColorTerm:   env.Str("COLORTERM"),
+		Multiplexed: multiplexed,
+	}
+	caps.DA1 = probe(tty, queryDA1)
+	caps.DA2 = probe(tty, queryDA2)
+	if r, g, b, err := GetBackgroundColor(tty); err == nil {
+		caps.HasBackground = true
+		caps.Background = [3]float64{r, g, b}
+	}
+	caps.TrueColor = parseTrueColorReply(probe(tty, queryTrueColor))
+	caps.SyncUpdate, _, _ = tty.QueryMode(2026)
+	caps.BracketedPaste, _, _ = tty.QueryMode(2004)
+	_, caps.KittyKeyboard = parseKittyFlags(probe(tty, queryKittyKeyboard))
+	return caps
+}
+
+// Apply feeds the findings back into the package: when the terminal
+// reported back a 24-bit color, HasTrueColor returns true and the color
+// functions emit true color escapes, and when it supports bracketed paste,
+// HasBracketedPaste returns true. Support that was detected from the
+// environment is not turned off by findings that are false.
+func (caps Capabilities) Apply() {
+	if caps.TrueColor && !probedTrueColor.Swap(true) {
+		extCache.Clear() // the cached escapes may have been downsampled
+	}
+	if caps.BracketedPaste {
+		probedBracketedPaste.Store(true)
+	}
+}
+
+// probe writes query to the terminal and returns the response, or "" if
+// there was no response
+func probe(tty *TTY, query string) string {
+	if err := tty.WriteString(query); err != nil {
+		return ""
+	}
+	s, err := tty.ReadStringKeepTiming()
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+// QueryMode asks the terminal about a DEC private mode with DECRQM, for
+// instance 2004 for bracketed paste, 1000 and 1006 for mouse reporting or
+// 2026 for synchronized output. supported is true if the terminal knows
+// the mode and it is not permanently reset, and set is true if the mode is
+// currently enabled. Terminals that do not understand DECRQM do not reply,
+// in which case both are false.
+func (tty *TTY) QueryMode(mode int) (supported bool, set bool, err error) {
+	if err := tty.WriteString("\033[?" + strconv.Itoa(mode) + "$p"); err != nil {
+		return false, false, err
+	}
+	s, _ := tty.ReadStringKeepTiming() // an error here means that there was no reply
+	state, ok := parseDECRPM(s, mode)
+	if !ok {
+		return false, false, nil
+	}
+	return state >= 1 && state <= 3, state == 1 || state == 3, nil
+}
+
+// parseDECRPM parses a DECRPM response ("ESC [ ? mode ; state $ y") for the
+// given private mode. The state is 0 (not recognized), 1 (set), 2 (reset),
+// 3 (permanently set) or 4 (permanently reset).
+func parseDECRPM(s string, mode int) (int, bool) {
+	prefix := "\033[?" + strconv.Itoa(mode) + ";"
+	_, after, ok := strings.Cut(s, prefix)
+	if !ok {
+		return 0, false
+	}
+	digits, _, ok := strings.Cut(after, "$y")
+	if !ok {
+		return 0, false
+	}
+	state, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0, false
+	}
+	return state, true
+}
+
+// parseKittyFlags parses a kitty keyboard protocol flags response ("ESC [ ? flags u")
+func parseKittyFlags(s string) (int, bool) {
+	_, after, ok := strings.Cut(s, "\033[?")
+	if !ok {
+		return 0, false
+	}
+	digits, _, ok := strings.Cut(after, "u")
+	if !ok {
+		return 0, false
+	}
+	flags, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0, false
+	}
+	return flags, true
+}
+
+// parseTrueColorReply checks if a DECRQSS response to queryTrueColor
+// contains the RGB value that was set, in either the ";" or ":" notation
+func parseTrueColorReply(s string) bool {
+	if !strings.Contains(s, "\033P1$r") {
+		return false
+	}
+	return strings.Contains(s, "2;1;2;3") || strings.Contains(s, "2:1:2:3") || strings.Contains(s, "2::1:2:3")
+}
+
+// String returns a readable report, suitable for pasting into bug reports
+func (caps Capabilities) String() string {
+	var sb strings.Builder
+	yesNo := func(b bool) string {
+		if b {
+			return "yes"
+		}
+		return "no"
+	}
+	fmt.Fprintf(&sb, "TERM:                   %s\n", caps.Term)
+	fmt.Fprintf(&sb, "COLORTERM:              %s\n", caps.ColorTerm)
+	fmt.Fprintf(&sb, "Multiplexed:            %s\n", yesNo(caps.Multiplexed))
+	fmt.Fprintf(&sb, "DA1:                    %q\n", caps.DA1)
+	fmt.Fprintf(&sb, "DA2:                    %q\n", caps.DA2)
+	if caps.HasBackground {
+		fmt.Fprintf(&sb, "Background:             %.3f, %.3f, %.3f\n", caps.Background[0], caps.Background[1], caps.Background[2])
+	} else {
+		sb.WriteString("Background:             unknown\n")
+	}
+	fmt.Fprintf(&sb, "True color (probed):    %s\n", yesNo(caps.TrueColor))
+	fmt.Fprintf(&sb, "True color (env):       %s\n", yesNo(HasTrueColor()))
+	fmt.Fprintf(&sb, "256 colors (env):       %s\n", yesNo(Has256Colors()))
+	fmt.Fprintf(&sb, "Synchronized output:    %s\n", yesNo(caps.SyncUpdate))
+	fmt.Fprintf(&sb, "Bracketed paste:        %s\n", yesNo(caps.BracketedPaste))
+	fmt.Fprintf(&sb, "Kitty keyboard:         %s\n", yesNo(caps.KittyKeyboard))
+	return sb.String()
+}
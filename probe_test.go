@@ -0,0 +1,58 @@
+package vt
+
+import "testing"
+
+func TestParseDECRPM(t *testing.T) {
+	if state, ok := parseDECRPM("\033[?2026;2$y", 2026); !ok || state != 2 {
+		t.Errorf("got (%d, %v), want (2, true)", state, ok)
+	}
+	if _, ok := parseDECRPM("\033[?25;1$y", 2026); ok {
+		t.Error("a response for another mode should not match")
+	}
+	if _, ok := parseDECRPM("", 2026); ok {
+		t.Error("an empty response should not match")
+	}
+}
+
+func TestParseKittyFlags(t *testing.T) {
+	if flags, ok := parseKittyFlags("\033[?1u"); !ok || flags != 1 {
+		t.Errorf("got (%d, %v), want (1, true)", flags, ok)
+	}
+	if _, ok := parseKittyFlags("\033[?62;22c"); ok {
+		t.Error("a DA1 response should not match")
+	}
+}
+
+func TestParseTrueColorReply(t *testing.T) {
+	if !parseTrueColorReply("\033P1$r0;38:2::1:2:3m\033\\") {
+		t.Error("expected a matching reply to be recognized")
+	}
+	if parseTrueColorReply("\033P1$r0;38;5;16m\033\\") {
+		t.Error("a degraded 256-color reply should not count as true color")
+	}
+}
+
+func TestCapabilitiesApply(t *testing.T) {
+	trueColor, bracketedPaste := probedTrueColor.Load(), probedBracketedPaste.Load()
+	t.Cleanup(func() {
+		probedTrueColor.Store(trueColor)
+		probedBracketedPaste.Store(bracketedPaste)
+		extCache.Clear()
+	})
+	probedTrueColor.Store(false)
+	probedBracketedPaste.Store(false)
+
+	Capabilities{}.Apply()
+	if HasBracketedPaste() {
+		t.Error("expected no bracketed paste support before a probe found it")
+	}
+	Capabilities{TrueColor: true, BracketedPaste: true}.Apply()
+	if !HasTrueColor() || !HasBracketedPaste() {
+		t.Error("expected the probed support to be applied")
+	}
+	if !EnvNoColor {
+		if got, want := RGB(1, 2, 3).String(), "\033[38;2;1;2;3m"; got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+}
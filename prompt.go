@@ -0,0 +1,79 @@
+package vt
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Confirm prints question followed by " [y/N] " and reads keys until y or n
+// is pressed, ignoring case. Returns true for y. Enter means no, and Esc,
+// Ctrl-C and Ctrl-D cancel, which also means no. The terminal is restored
+// before returning.
+func Confirm(question string) bool {
+	tty, err := NewTTY()
+	if err != nil {
+		return false
+	}
+	return prompt(tty, os.Stdout, question+" [y/N]", []rune{'y', 'n'}, 'n') == 'y'
+}
+
+// Prompt prints question followed by the valid choices, like "[a/b/c]",
+// and reads keys until one of the valid runes is pressed, ignoring case.
+// The choice is echoed and returned. Esc, Ctrl-C and Ctrl-D cancel the
+// prompt, in which case 0 is returned. The terminal is restored before
+// returning.
+func Prompt(question string, valid []rune) rune {
+	tty, err := NewTTY()
+	if err != nil {
+		return 0
+	}
+	choices := make([]string, len(valid))
+	for i, r := range valid {
+		choices[i] = string(r)
+	}
+	return prompt(tty, os.Stdout, question+" ["+strings.Join(choices, "/")+"]", valid, 0)
+}
+
+// prompt writes question to w, reads a choice from tty with readChoice,
+// closes tty and echoes the choice
+func prompt(tty *TTY, w io.Writer, question string, valid []rune, enter rune) rune {
+	fmt.Fprint(w, question+" ")
+	choice := readChoice(tty, valid, enter)
+	tty.Close()
+	if choice != 0 {
+		fmt.Fprint(w, string(choice))
+	}
+	fmt.Fprintln(w)
+	return choice
+}
+
+// readChoice reads keys until one of the valid runes is pressed, ignoring
+// case, and returns it. If enter is not 0, it is returned for Enter.
+// Returns 0 for Esc, Ctrl-C, Ctrl-D or end of input.
+func readChoice(tty *TTY, valid []rune, enter rune) rune {
+	for {
+		key := tty.ReadKey()
+		switch key {
+		case "", "c:3", "c:4", "c:27":
+			return 0
+		case "c:13":
+			if enter != 0 {
+				return enter
+			}
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(key)
+		if size != len(key) {
+			continue // a special key
+		}
+		for _, v := range valid {
+			if unicode.ToLower(r) == unicode.ToLower(v) {
+				return v
+			}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package vt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"unicode/utf8"
+)
+
+// ErrInterrupted is returned by ReadLine and ReadPassword when Ctrl-C is
+// pressed
+var ErrInterrupted = errors.New("interrupted")
+
+// ReadLine prints prompt and reads a line of text, with line editing:
+// Left, Right, Home, End, Ctrl-A and Ctrl-E move the cursor, Backspace and
+// Delete remove runes, and Up and Down go through history, which is
+// ordered from the oldest to the newest entry. Enter returns the line.
+// Ctrl-C returns ErrInterrupted, and Ctrl-D on an empty line, or the end
+// of the input, returns io.EOF. The terminal is in raw mode while reading,
+// and is restored before returning.
+func (tty *TTY) ReadLine(prompt string, history []string) (string, error) {
+	return readLine(tty, os.Stdout, prompt, history, true)
+}
+
+// ReadPassword is like ReadLine, but the typed text is not shown, and
+// there is no history
+func (tty *TTY) ReadPassword(prompt string) (string, error) {
+	return readLine(tty, os.Stdout, prompt, nil, false)
+}
+
+// readLine implements ReadLine and ReadPassword, writing the prompt and the
+// line to w. The line is only shown if echo is true.
+func readLine(tty *TTY, w io.Writer, prompt string, history []string, echo bool) (string, error) {
+	tty.RawMode()
+	defer tty.Restore()
+
+	var line []rune
+	cursor := 0               // the position of the cursor in line
+	histIndex := len(history) // len(history) is the line being edited
+	var draft []rune          // the edited line, while going through history
+
+	render := func() {
+		if !echo {
+			return
+		}
+		s := "\r" + prompt + string(line) + "\033[K"
+		if back := VisibleWidth(string(line[cursor:])); back > 0 {
+			s += fmt.Sprintf("\033[%dD", back)
+		}
+		io.WriteString(w, s)
+	}
+	setLine := func(s []rune) {
+		line = slices.Clone(s)
+		cursor = len(line)
+	}
+
+	io.WriteString(w, prompt)
+	for {
+		key := tty.ReadKey()
+		switch key {
+		case "":
+			io.WriteString(w, "\r\n")
+			return string(line), io.EOF
+		case "c:3": // Ctrl-C
+			io.WriteString(w, "\r\n")
+			return "", ErrInterrupted
+		case "c:4": // Ctrl-D
+			if len(line) == 0 {
+				io.WriteString(w, "\r\n")
+				return "", io.EOF
+			}
+			continue
+		case "c:13", "c:10": // Enter
+			io.WriteString(w, "\r\n")
+			return string(line), nil
+		case "c:127", "c:8": // Backspace
+			if cursor > 0 {
+				line = slices.Delete(line, cursor-1, cursor)
+				cursor--
+			}
+		case "⌦": // Delete
+			if cursor < len(line) {
+				line = slices.Delete(line, cursor, cursor+1)
+			}
+		case "←":
+			cursor = max(cursor-1, 0)
+		case "→":
+			cursor = min(cursor+1, len(line))
+		case "⇱", "c:1": // Home, Ctrl-A
+			cursor = 0
+		case "⇲", "c:5": // End, Ctrl-E
+			cursor = len(line)
+		case "↑":
+			if histIndex == 0 {
+				continue
+			}
+			if histIndex == len(history) {
+				draft = slices.Clone(line)
+			}
+			histIndex--
+			setLine([]rune(history[histIndex]))
+		case "↓":
+			if histIndex == len(history) {
+				continue
+			}
+			histIndex++
+			if histIndex == len(history) {
+				setLine(draft)
+			} else {
+				setLine([]rune(history[histIndex]))
+			}
+		default:
+			r, size := utf8.DecodeRuneInString(key)
+			if size != len(key) || runeWidth(r) == 0 {
+				continue // a special key or a control character
+			}
+			line = slices.Insert(line, cursor, r)
+			cursor++
+		}
+		render()
+	}
+}
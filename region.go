@@ -0,0 +1,270 @@
+package vt
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// regionVersion is the format version tag emitted by Canvas.ExportRegion.
+// Version 2 added the text attributes to the runs.
+const regionVersion = 2
+
+// Cell kinds used in the run legend of an exported region.
+// Text cells have no kind in the legend.
+const (
+	regionText  = ""
+	regionEmpty = "empty" // rune 0
+	regionWide  = "wide"  // pairs of a wide rune and its continuation cell
+	regionBase  = "base"  // a wide rune without a continuation cell
+	regionCont  = "cont"  // a continuation cell without a wide rune
+)
+
+// regionKind returns the legend kind of row[i] and how many cells it spans
+func regionKind(row []ColorRune, i int) (string, int) {
+	cr := row[i]
+	switch cr.cw {
+	case 2:
+		if i+1 < len(row) && row[i+1].cw == 1 && row[i+1].fg == cr.fg && row[i+1].bg == cr.bg && row[i+1].attr == cr.attr {
+			return regionWide, 2
+		}
+		return regionBase, 1
+	case 1:
+		return regionCont, 1
+	}
+	if cr.r == 0 {
+		return regionEmpty, 1
+	}
+	return regionText, 1
+}
+
+// writeRegionRune writes r to sb, escaping backslashes and non-printable runes
+func writeRegionRune(sb *strings.Builder, r rune) {
+	switch {
+	case r == '\\':
+		sb.WriteString(`\\`)
+	case unicode.IsPrint(r):
+		sb.WriteRune(r)
+	default:
+		fmt.Fprintf(sb, `\u{%x}`, r)
+	}
+}
+
+// parseRegionRow decodes the runes between the | delimiters of a row line
+func parseRegionRow(line string) ([]rune, error) {
+	if len(line) < 2 || line[0] != '|' || line[len(line)-1] != '|' {
+		return nil, fmt.Errorf("invalid region row %q", line)
+	}
+	s := line[1 : len(line)-1]
+	var runes []rune
+	for s != "" {
+		if !strings.HasPrefix(s, `\`) {
+			r, size := utf8.DecodeRuneInString(s)
+			runes = append(runes, r)
+			s = s[size:]
+			continue
+		}
+		if strings.HasPrefix(s, `\\`) {
+			runes = append(runes, '\\')
+			s = s[2:]
+			continue
+		}
+		end := strings.IndexByte(s, '}')
+		if !strings.HasPrefix(s, `\u{`) || end < 0 {
+			return nil, fmt.Errorf("invalid escape in region row %q", line)
+		}
+		n, err := strconv.ParseUint(s[3:end], 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid escape in region row %q: %w", line, err)
+		}
+		runes = append(runes, rune(n))
+		s = s[end+1:]
+	}
+	return runes, nil
+}
+
+// ExportRegion returns a human-readable text representation of the w x h
+// region with its top left corner at (x, y). The region is clipped to the
+// canvas. The format is:
+//
+//	vt-region <version> w=<W> h=<H>
+//	|<row 0>|
+//	...
+//	|<row H-1>|
+//	runs
+//	<y> <x> <n> <fg> <bg> <attr> [kind]
+//	...
+//
+// Each row holds one rune per cell, except that the continuation cell of a
+// wide rune is left out, so that rows line up when viewed in a terminal.
+// Empty cells are shown as spaces, while backslashes and non-printable
+// runes are escaped as \\ and \u{hex}. The runs list every cell of the
+// region as runs of cells with the same colors, text attributes and kind,
+// where colors are hexadecimal AttributeColor values, attr is a hexadecimal
+// TextAttr value and kind is one of empty, wide, base or cont. The output
+// can be read back with ImportRegion.
+func (c *Canvas) ExportRegion(x, y, w, h uint) string {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+
+	if x >= c.w || y >= c.h {
+		w, h = 0, 0
+	}
+	w = umin(w, c.w-min(x, c.w))
+	h = umin(h, c.h-min(y, c.h))
+
+	var rows, runs strings.Builder
+	fmt.Fprintf(&rows, "vt-region %d w=%d h=%d\n", regionVersion, w, h)
+	for j := range h {
+		start := (y+j)*c.w + x
+		row := c.chars[start : start+w]
+		rows.WriteByte('|')
+		runStart, runLen := 0, 0
+		runKind := regionText
+		flush := func() {
+			if runLen == 0 {
+				return
+			}
+			cr := row[runStart]
+			fmt.Fprintf(&runs, "%d %d %d %08x %08x %02x", j, runStart, runLen, uint64(cr.fg), uint64(cr.bg), uint8(cr.attr))
+			if runKind != regionText {
+				runs.WriteString(" " + runKind)
+			}
+			runs.WriteByte('\n')
+		}
+		for i := 0; i < len(row); {
+			kind, span := regionKind(row, i)
+			cr := row[i]
+			switch kind {
+			case regionEmpty:
+				rows.WriteByte(' ')
+			case regionText, regionWide, regionBase:
+				writeRegionRune(&rows, cr.r)
+			}
+			if runLen == 0 || kind != runKind || cr.fg != row[runStart].fg || cr.bg != row[runStart].bg || cr.attr != row[runStart].attr {
+				flush()
+				runStart, runLen, runKind = i, 0, kind
+			}
+			runLen += span
+			i += span
+		}
+		flush()
+		rows.WriteString("|\n")
+	}
+	rows.WriteString("runs\n")
+	rows.WriteString(runs.String())
+	return rows.String()
+}
+
+// ImportRegion reads a region produced by ExportRegion and places it on the
+// canvas with its top left corner at (x, y). The imported cells are marked
+// as undrawn. Regions from version 1 of the format, which had no text
+// attributes, can also be read. An error is returned if data is malformed
+// or if the region does not fit on the canvas.
+func (c *Canvas) ImportRegion(x, y uint, data string) error {
+	lines := strings.Split(strings.TrimSuffix(data, "\n"), "\n")
+	var version, w, h uint
+	if _, err := fmt.Sscanf(lines[0], "vt-region %d w=%d h=%d", &version, &w, &h); err != nil {
+		return fmt.Errorf("invalid region header %q: %w", lines[0], err)
+	}
+	if version < 1 || version > regionVersion {
+		return fmt.Errorf("unsupported region version %d", version)
+	}
+	// The number of numeric fields of a run
+	numFields := 6
+	if version == 1 {
+		numFields = 5
+	}
+	if uint(len(lines)) < h+2 || lines[h+1] != "runs" {
+		return errors.New("region is missing rows or runs")
+	}
+
+	cells := make([]ColorRune, w*h)
+	kinds := make([]string, w*h)
+	covered := make([]bool, w*h)
+	for _, line := range lines[h+2:] {
+		fields := strings.Fields(line)
+		if len(fields) != numFields && len(fields) != numFields+1 {
+			return fmt.Errorf("invalid region run %q", line)
+		}
+		var nums [6]uint64
+		for i, base := range []int{10, 10, 10, 16, 16, 16}[:numFields] {
+			n, err := strconv.ParseUint(fields[i], base, 64)
+			if err != nil {
+				return fmt.Errorf("invalid region run %q: %w", line, err)
+			}
+			nums[i] = n
+		}
+		j, i, n := uint(nums[0]), uint(nums[1]), uint(nums[2])
+		kind := regionText
+		if len(fields) == numFields+1 {
+			kind = fields[numFields]
+		}
+		switch {
+		case j >= h || i+n > w:
+			return fmt.Errorf("region run %q is out of bounds", line)
+		case kind == regionWide && n%2 != 0:
+			return fmt.Errorf("region run %q splits a wide rune", line)
+		case kind != regionText && kind != regionEmpty && kind != regionWide && kind != regionBase && kind != regionCont:
+			return fmt.Errorf("unknown cell kind in region run %q", line)
+		}
+		for k := range n {
+			index := j*w + i + k
+			cr := &cells[index]
+			cr.fg = AttributeColor(nums[3])
+			cr.bg = AttributeColor(nums[4])
+			cr.attr = TextAttr(nums[5])
+			switch kind {
+			case regionWide:
+				cr.cw = 2 - uint8(k%2)
+			case regionBase:
+				cr.cw = 2
+			case regionCont:
+				cr.cw = 1
+			}
+			kinds[index] = kind
+			covered[index] = true
+		}
+	}
+	for index, ok := range covered {
+		if !ok {
+			return fmt.Errorf("region cell (%d, %d) is not covered by any run", uint(index)%w, uint(index)/w)
+		}
+	}
+
+	for j := range h {
+		runes, err := parseRegionRow(lines[1+j])
+		if err != nil {
+			return err
+		}
+		for i := range w {
+			index := j*w + i
+			if cells[index].cw == 1 {
+				continue // continuation cells have no rune of their own
+			}
+			if len(runes) == 0 {
+				return fmt.Errorf("region row %d is too short", j)
+			}
+			if kinds[index] != regionEmpty {
+				cells[index].r = runes[0]
+			}
+			runes = runes[1:]
+		}
+		if len(runes) != 0 {
+			return fmt.Errorf("region row %d is too long", j)
+		}
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if x+w > c.w || y+h > c.h {
+		return errors.New("region does not fit on the canvas")
+	}
+	for j := range h {
+		copy(c.chars[(y+j)*c.w+x:], cells[j*w:(j+1)*w])
+	}
+	return nil
+}
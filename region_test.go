@@ -0,0 +1,115 @@
+package vt
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func regionFixture() *Canvas {
+	c := NewCanvasWithSize(12, 4)
+	c.WriteString(1, 0, Default, DefaultBackground, `a\b`)
+	c.WriteWideRuneB(5, 0, Red, BackgroundBlue, '日')
+	c.WriteWideRuneB(7, 0, Red, BackgroundBlue, '本')
+	c.WriteString(0, 1, TrueColor(10, 20, 30).Combine(Bold), TrueBackground(200, 100, 0), "heavy")
+	c.WriteRune(6, 1, Color256(123), Background256(45), '\x01')
+	c.WriteString(0, 2, Green.Combine(Underscore), DefaultBackground, "x y")
+	c.WriteWideRuneB(10, 3, Yellow, DefaultBackground, '語')
+	c.WriteStringStyled(4, 2, Blue, DefaultBackground, AttrItalic|AttrReverse, "it")
+	return c
+}
+
+func TestExportRegionFormat(t *testing.T) {
+	c := NewCanvasWithSize(6, 2)
+	c.WriteString(0, 0, Default, DefaultBackground, "ab")
+	c.WriteWideRuneB(2, 0, Red, DefaultBackground, '日')
+	c.WriteRuneStyled(1, 0, Default, DefaultBackground, AttrBold, 'b')
+	got := c.ExportRegion(0, 0, 5, 1)
+	want := "vt-region 2 w=5 h=1\n" +
+		"|ab日 |\n" +
+		"runs\n" +
+		"0 0 1 " + hex8(Default) + " " + hex8(DefaultBackground) + " 00\n" +
+		"0 1 1 " + hex8(Default) + " " + hex8(DefaultBackground) + " 01\n" +
+		"0 2 2 " + hex8(Red) + " " + hex8(DefaultBackground) + " 00 wide\n" +
+		"0 4 1 " + hex8(Default) + " " + hex8(DefaultBackground) + " 00 empty\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func hex8(ac AttributeColor) string {
+	return fmt.Sprintf("%08x", uint64(ac))
+}
+
+func TestRegionRoundTrip(t *testing.T) {
+	src := regionFixture()
+	for _, tc := range []struct {
+		name       string
+		x, y, w, h uint
+	}{
+		{"whole", 0, 0, 12, 4},
+		{"cjk", 4, 0, 6, 1},
+		{"attributes", 0, 1, 8, 2},
+		{"split wide rune", 6, 0, 2, 1},
+		{"clipped", 9, 2, 10, 10},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data := src.ExportRegion(tc.x, tc.y, tc.w, tc.h)
+			dst := NewCanvasWithSize(14, 6)
+			if err := dst.ImportRegion(1, 2, data); err != nil {
+				t.Fatalf("ImportRegion: %v\n%s", err, data)
+			}
+			w, h := min(tc.w, 12-tc.x), min(tc.h, 4-tc.y)
+			if again := dst.ExportRegion(1, 2, w, h); again != data {
+				t.Errorf("round trip mismatch:\n%s\nvs\n%s", data, again)
+			}
+			for j := range h {
+				for i := range w {
+					a := src.chars[(tc.y+j)*src.w+tc.x+i]
+					b := dst.chars[(2+j)*dst.w+1+i]
+					if a.r != b.r || a.fg != b.fg || a.bg != b.bg || a.cw != b.cw || a.attr != b.attr {
+						t.Errorf("cell (%d, %d): got %+v, want %+v", i, j, b, a)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestImportRegionErrors(t *testing.T) {
+	c := NewCanvasWithSize(4, 2)
+	valid := NewCanvasWithSize(3, 1).ExportRegion(0, 0, 3, 1)
+	for _, data := range []string{
+		"",
+		"vt-region 3 w=1 h=1\n| |\nruns\n",
+		"vt-region 1 w=3 h=1\n|   |\n",
+		strings.Replace(valid, "|   |", "|  |", 1),
+		strings.Replace(valid, " empty", " bogus", 1),
+		strings.Replace(valid, "0 0 3", "0 0 2", 1),
+		NewCanvasWithSize(5, 1).ExportRegion(0, 0, 5, 1),
+	} {
+		if err := c.ImportRegion(0, 0, data); err == nil {
+			t.Errorf("expected an error for:\n%s", data)
+		}
+	}
+	if err := c.ImportRegion(1, 1, valid); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestImportRegionVersion1(t *testing.T) {
+	c := NewCanvasWithSize(3, 1)
+	data := "vt-region 1 w=2 h=1\n" +
+		"|ab|\n" +
+		"runs\n" +
+		"0 0 2 " + hex8(Red) + " " + hex8(DefaultBackground) + "\n"
+	if err := c.ImportRegion(1, 0, data); err != nil {
+		t.Fatalf("ImportRegion: %v", err)
+	}
+	if got := c.String(); got != " ab\n" {
+		t.Errorf("got %q", got)
+	}
+	if cell, _ := c.CellAt(2, 0); cell.Fg != Red || cell.Attr != 0 {
+		t.Errorf("got %+v", cell)
+	}
+}
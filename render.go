@@ -80,6 +80,10 @@ func (c *Canvas) ToImage() (image.Image, error) {
 // canvas-to-image rendering. True-color and 256-color values are decoded from
 // their embedded bits; standard ANSI codes are served from ansiRenderPalette.
 func ansiCodeToColor(ac AttributeColor) color.NRGBA {
+	if ac.isPair() {
+		first, _ := ac.pair()
+		return ansiCodeToColor(first)
+	}
 	code := uint32(ac)
 	if code&extendedFlag != 0 {
 		if code&trueColorFlag != 0 {
@@ -2,27 +2,63 @@ package vt
 
 import (
 	"os"
+	"sync/atomic"
 
 	"github.com/xyproto/env/v2"
 	"golang.org/x/term"
 )
 
-// MustTermSize returns the current terminal width and height
+// The maximum terminal size that is reported by MustTermSize. A bogus size
+// from a misbehaving terminal or environment variable could otherwise make
+// NewCanvas allocate gigabytes.
+var maxWidth, maxHeight atomic.Uint64
+
+func init() {
+	SetMaxCanvasSize(1000, 1000)
+}
+
+// SetMaxCanvasSize sets the largest width and height that MustTermSize
+// reports, and that NewCanvas and Resized use. The default is 1000x1000.
+func SetMaxCanvasSize(w, h uint) {
+	maxWidth.Store(uint64(max(w, 1)))
+	maxHeight.Store(uint64(max(h, 1)))
+}
+
+// MustTermSize returns the current terminal width and height, limited to
+// the size set with SetMaxCanvasSize
 func MustTermSize() (uint, uint) {
+	w, h, _ := termSize()
+	return w, h
+}
+
+// termSize returns the current terminal width and height, limited to the
+// maximum canvas size, and where the size came from: "terminal", "COLS",
+// "COLUMNS" or "default"
+func termSize() (uint, uint, string) {
+	w, h, source := detectTermSize()
+	return min(w, uint(maxWidth.Load())), min(h, uint(maxHeight.Load())), source
+}
+
+// detectTermSize returns the terminal width and height, and where the size
+// came from
+func detectTermSize() (uint, uint, string) {
 	fd := int(os.Stdout.Fd())
 	if term.IsTerminal(fd) {
 		width, height, err := term.GetSize(fd)
 		if err == nil {
-			return uint(width), uint(height)
+			return uint(width), uint(height), "terminal"
 		}
 	}
 
 	// Fallback to environment variables
 	var w uint = 79
+	source := "default"
 	if cols := env.Int("COLS", 0); cols > 0 {
 		w = uint(cols)
+		source = "COLS"
 	} else if cols := env.Int("COLUMNS", 0); cols > 0 {
 		w = uint(cols)
+		source = "COLUMNS"
 	}
-	return w, uint(env.Int("LINES", 25))
+	return w, uint(env.Int("LINES", 25)), source
 }
@@ -0,0 +1,137 @@
+package vt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// canvasSnapshotMagic starts a marshaled CanvasSnapshot, followed by a
+// version byte
+const canvasSnapshotMagic = "vtsnap"
+
+// canvasSnapshotVersion is the format version of a marshaled CanvasSnapshot.
+// Version 2 added the text attributes to each cell, and version 3 widened
+// the colors to 64 bits.
+const canvasSnapshotVersion = 3
+
+// Flags of a marshaled CanvasSnapshot
+const (
+	snapshotLineWrap = 1 << iota
+	snapshotCursorVisible
+)
+
+// canvasSnapshotCellSize is the number of bytes per marshaled cell: the
+// rune, the foreground and background colors, the cell width and the text
+// attributes
+const canvasSnapshotCellSize = 4 + 8 + 8 + 1 + 1
+
+// CanvasSnapshot is a saved state of a Canvas, see Canvas.SaveSnapshot
+type CanvasSnapshot struct {
+	chars         []ColorRune
+	w             uint
+	h             uint
+	lineWrap      bool
+	cursorVisible bool
+}
+
+// SaveSnapshot returns the current cells of the canvas, together with its
+// size and its line wrap and cursor settings, so that they can be restored
+// with Restore, for instance for undo or for animations
+func (c *Canvas) SaveSnapshot() CanvasSnapshot {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	return CanvasSnapshot{
+		chars:         append([]ColorRune(nil), c.chars...),
+		w:             c.w,
+		h:             c.h,
+		lineWrap:      c.lineWrap,
+		cursorVisible: c.cursorVisible,
+	}
+}
+
+// Restore overwrites the canvas with a snapshot from SaveSnapshot. All
+// cells are redrawn by the next Draw. An error is returned if the snapshot
+// does not have the same size as the canvas.
+func (c *Canvas) Restore(snap CanvasSnapshot) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if snap.w != c.w || snap.h != c.h {
+		return fmt.Errorf("snapshot is %dx%d, but the canvas is %dx%d", snap.w, snap.h, c.w, c.h)
+	}
+	copy(c.chars, snap.chars)
+	for i := range c.chars {
+		c.chars[i].drawn = false
+	}
+	c.oldchars = nil
+	c.lineWrap = snap.lineWrap
+	c.cursorVisible = snap.cursorVisible
+	return nil
+}
+
+// Size returns the width and height of the snapshot
+func (snap CanvasSnapshot) Size() (uint, uint) {
+	return snap.w, snap.h
+}
+
+// Marshal encodes the snapshot as bytes, which can be decoded again with
+// UnmarshalCanvasSnapshot
+func (snap CanvasSnapshot) Marshal() []byte {
+	b := make([]byte, 0, len(canvasSnapshotMagic)+1+4+4+1+len(snap.chars)*canvasSnapshotCellSize)
+	b = append(b, canvasSnapshotMagic...)
+	b = append(b, canvasSnapshotVersion)
+	b = binary.BigEndian.AppendUint32(b, uint32(snap.w))
+	b = binary.BigEndian.AppendUint32(b, uint32(snap.h))
+	var flags byte
+	if snap.lineWrap {
+		flags |= snapshotLineWrap
+	}
+	if snap.cursorVisible {
+		flags |= snapshotCursorVisible
+	}
+	b = append(b, flags)
+	for _, cr := range snap.chars {
+		b = binary.BigEndian.AppendUint32(b, uint32(cr.r))
+		b = binary.BigEndian.AppendUint64(b, uint64(cr.fg))
+		b = binary.BigEndian.AppendUint64(b, uint64(cr.bg))
+		b = append(b, cr.cw, byte(cr.attr))
+	}
+	return b
+}
+
+// UnmarshalCanvasSnapshot decodes a snapshot that was encoded with
+// CanvasSnapshot.Marshal
+func UnmarshalCanvasSnapshot(data []byte) (CanvasSnapshot, error) {
+	const headerSize = len(canvasSnapshotMagic) + 1 + 4 + 4 + 1
+	if len(data) < headerSize || string(data[:len(canvasSnapshotMagic)]) != canvasSnapshotMagic {
+		return CanvasSnapshot{}, errors.New("not a canvas snapshot")
+	}
+	data = data[len(canvasSnapshotMagic):]
+	if data[0] != canvasSnapshotVersion {
+		return CanvasSnapshot{}, fmt.Errorf("unsupported canvas snapshot version %d", data[0])
+	}
+	w, h := binary.BigEndian.Uint32(data[1:]), binary.BigEndian.Uint32(data[5:])
+	flags := data[9]
+	data = data[10:]
+	if uint64(len(data)) != uint64(w)*uint64(h)*canvasSnapshotCellSize {
+		return CanvasSnapshot{}, fmt.Errorf("canvas snapshot has %d bytes of cells, expected %d for %dx%d", len(data), uint64(w)*uint64(h)*canvasSnapshotCellSize, w, h)
+	}
+	snap := CanvasSnapshot{
+		chars:         make([]ColorRune, w*h),
+		w:             uint(w),
+		h:             uint(h),
+		lineWrap:      flags&snapshotLineWrap != 0,
+		cursorVisible: flags&snapshotCursorVisible != 0,
+	}
+	for i := range snap.chars {
+		cell := data[i*canvasSnapshotCellSize:]
+		snap.chars[i] = ColorRune{
+			r:    rune(binary.BigEndian.Uint32(cell)),
+			fg:   AttributeColor(binary.BigEndian.Uint64(cell[4:])),
+			bg:   AttributeColor(binary.BigEndian.Uint64(cell[12:])),
+			cw:   cell[20],
+			attr: TextAttr(cell[21]),
+		}
+	}
+	return snap, nil
+}
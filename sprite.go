@@ -0,0 +1,90 @@
+package vt
+
+// DrawSprite writes each string in lines on successive rows, starting at
+// (x, y), using the given colors. Wide runes (CJK, emoji) occupy two columns.
+// Anything that falls outside of the canvas is clipped.
+func (c *Canvas) DrawSprite(x, y uint, lines []string, fg, bg AttributeColor) {
+	c.drawSprite(x, y, lines, nil, fg, bg, false)
+}
+
+// CanvasFromLines returns a new canvas that is just large enough to hold
+// the given lines, with each rune placed as by DrawSprite and the given
+// colors used for every cell. Wide runes take up two cells.
+func CanvasFromLines(lines []string, fg, bg AttributeColor) *Canvas {
+	var w uint
+	for _, line := range lines {
+		var lw uint
+		for _, r := range line {
+			lw += uint(runeWidth(r))
+		}
+		w = max(w, lw)
+	}
+	c := NewCanvasWithSize(w, uint(len(lines)))
+	bgb := bg.Background()
+	for i := range c.chars {
+		c.chars[i] = ColorRune{fg: fg, bg: bgb, r: ' '}
+	}
+	c.drawSprite(0, 0, lines, nil, fg, bg, false)
+	return c
+}
+
+// DrawSpriteTransparent is like DrawSprite, but spaces in the sprite are
+// skipped, leaving the cells underneath untouched
+func (c *Canvas) DrawSpriteTransparent(x, y uint, lines []string, fg, bg AttributeColor) {
+	c.drawSprite(x, y, lines, nil, fg, bg, true)
+}
+
+// DrawColorSprite is like DrawSprite, but the foreground color of each rune
+// is looked up in colorMap. Runes that are not in colorMap are drawn with
+// the Default foreground color. This makes it possible to describe small
+// pixel-art graphics as data, for example {'R': Red, 'Y': Yellow, 'G': Green}.
+func (c *Canvas) DrawColorSprite(x, y uint, lines []string, colorMap map[rune]AttributeColor, bg AttributeColor) {
+	c.drawSprite(x, y, lines, colorMap, Default, bg, false)
+}
+
+// drawSprite is the shared implementation for DrawSprite, DrawSpriteTransparent
+// and DrawColorSprite. If colorMap is non-nil, it is used for looking up the
+// foreground color of each rune, with fg as the fallback.
+func (c *Canvas) drawSprite(x, y uint, lines []string, colorMap map[rune]AttributeColor, fg, bg AttributeColor, transparent bool) {
+	bgb := bg.Background()
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	x, y, _ = c.padded(x, y)
+	maxX, maxY := c.w-umin(c.padRight, c.w), c.h-umin(c.padBottom, c.h)
+	for i, line := range lines {
+		row := y + uint(i)
+		if row >= maxY {
+			break
+		}
+		col := x
+		for _, r := range line {
+			if col >= maxX {
+				break
+			}
+			rw := runeWidth(r)
+			if rw == 0 {
+				continue // combining marks and control characters have no cell of their own
+			}
+			if r == ' ' && transparent {
+				col++
+				continue
+			}
+			rfg := fg
+			if colorMap != nil {
+				if mapped, ok := colorMap[r]; ok {
+					rfg = mapped
+				}
+			}
+			if rw == 2 {
+				if col+1 >= maxX {
+					break
+				}
+				c.WriteWideRuneBNoLock(col, row, rfg, bgb, r)
+				col += 2
+				continue
+			}
+			c.WriteRuneBNoLock(col, row, rfg, bgb, r)
+			col++
+		}
+	}
+}
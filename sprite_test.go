@@ -0,0 +1,60 @@
+package vt
+
+import "testing"
+
+func TestDrawSprite(t *testing.T) {
+	c := NewCanvasWithSize(5, 3)
+	c.DrawSprite(1, 0, []string{"/\\", "\\/"}, Red, Default)
+	if got, want := c.String(), " /\\  \n \\/  \n     \n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestDrawSpriteTransparent(t *testing.T) {
+	c := NewCanvasWithSize(3, 1)
+	c.WriteString(0, 0, Default, Default, "abc")
+	c.DrawSpriteTransparent(0, 0, []string{"x x"}, Red, Default)
+	if got, want := c.String(), "xbx\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestDrawSpriteWideRunes(t *testing.T) {
+	c := NewCanvasWithSize(6, 1)
+	c.DrawSprite(0, 0, []string{"日本x"}, Default, Default)
+	if r, _ := c.At(2, 0); r != '本' {
+		t.Errorf("expected 本 at column 2, got %q", r)
+	}
+	if r, _ := c.At(4, 0); r != 'x' {
+		t.Errorf("expected x at column 4, got %q", r)
+	}
+}
+
+func TestDrawColorSprite(t *testing.T) {
+	c := NewCanvasWithSize(3, 1)
+	c.DrawColorSprite(0, 0, []string{"RGx"}, map[rune]AttributeColor{'R': Red, 'G': Green}, Default)
+	for x, want := range []AttributeColor{Red, Green, Default} {
+		if got := c.chars[x].fg; got != want {
+			t.Errorf("fg at %d: got %d, want %d", x, got, want)
+		}
+	}
+}
+
+func TestCanvasFromLines(t *testing.T) {
+	c := CanvasFromLines([]string{"ab", "日本x", ""}, Red, Blue)
+	if c.W() != 5 || c.H() != 3 {
+		t.Fatalf("got a %dx%d canvas, want 5x3", c.W(), c.H())
+	}
+	if r, _ := c.At(2, 1); r != '本' {
+		t.Errorf("expected 本 at column 2, got %q", r)
+	}
+	if r, _ := c.At(4, 1); r != 'x' {
+		t.Errorf("expected x at column 4, got %q", r)
+	}
+	if r, _ := c.At(4, 0); r != ' ' {
+		t.Errorf("expected the rest of a short line to be blank, got %q", r)
+	}
+	if cr := c.chars[c.W()*2]; cr.fg != Red || cr.bg != Blue.Background() {
+		t.Errorf("unexpected colors for an empty cell: %+v", cr)
+	}
+}
@@ -0,0 +1,70 @@
+package vt
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/xyproto/env/v2"
+)
+
+// utf8Locale is true if the locale uses UTF-8, according to the first of
+// LC_ALL, LC_CTYPE and LANG that is set. Windows terminals are assumed to
+// handle UTF-8.
+var utf8Locale = func() bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := strings.ToLower(env.Str(name)); locale != "" {
+			return strings.Contains(locale, "utf-8") || strings.Contains(locale, "utf8")
+		}
+	}
+	return runtime.GOOS == "windows"
+}()
+
+// statusLine writes a colored glyph followed by msg to w, if output is
+// enabled. The ASCII prefix is used instead of the glyph if the locale does
+// not use UTF-8.
+func (o *TextOutput) statusLine(w io.Writer, color AttributeColor, glyph, ascii, msg string) {
+	if !o.enabled {
+		return
+	}
+	prefix := ascii
+	if utf8Locale {
+		prefix = glyph
+	}
+	if o.color {
+		prefix = color.Get(prefix)
+	}
+	fmt.Fprintln(w, prefix+" "+msg)
+}
+
+// Success writes a green ✓ (or [OK]) followed by msg to stdout, if output is enabled
+func (o *TextOutput) Success(msg string) {
+	o.statusLine(os.Stdout, LightGreen, "✓", "[OK]", msg)
+}
+
+// Warn writes a yellow ⚠ (or [WARN]) followed by msg to stderr, if output is enabled
+func (o *TextOutput) Warn(msg string) {
+	o.statusLine(os.Stderr, LightYellow, "⚠", "[WARN]", msg)
+}
+
+// Fail writes a red ✗ (or [ERR]) followed by msg to stderr, if output is enabled
+func (o *TextOutput) Fail(msg string) {
+	o.statusLine(os.Stderr, LightRed, "✗", "[ERR]", msg)
+}
+
+// Success writes a green ✓ (or [OK]) followed by msg to stdout
+func Success(msg string) {
+	New().Success(msg)
+}
+
+// Warn writes a yellow ⚠ (or [WARN]) followed by msg to stderr
+func Warn(msg string) {
+	New().Warn(msg)
+}
+
+// Fail writes a red ✗ (or [ERR]) followed by msg to stderr
+func Fail(msg string) {
+	New().Fail(msg)
+}
@@ -0,0 +1,90 @@
+package vt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReserveStatusLine reserves the last row of the canvas for a status or
+// command line. Draw, Redraw and DrawRegion leave that row alone, and it
+// is written with SetStatus instead, which only writes that one row. This
+// keeps a status line in place without redrawing it along with the rest of
+// the canvas, or managing a scroll region.
+func (c *Canvas) ReserveStatusLine() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.statusLine = true
+}
+
+// drawHeight returns the number of rows that are drawn by Draw, which is
+// the height of the canvas minus the reserved status line, if any. The
+// canvas mutex must be held.
+func (c *Canvas) drawHeight() uint {
+	if c.statusLine && c.h > 0 {
+		return c.h - 1
+	}
+	return c.h
+}
+
+// SetStatus writes s to the status line that is reserved with
+// ReserveStatusLine, and writes the status line to the terminal right
+// away. The rest of the row is filled with spaces in the bg color, and s
+// is truncated with '…' if it is too wide. Escape sequences in s are
+// removed. Nothing is written if no status line has been reserved.
+func (c *Canvas) SetStatus(fg, bg AttributeColor, s string) {
+	bgb := bg.Background()
+	c.mut.Lock()
+	if !c.statusLine || c.w == 0 || c.h == 0 {
+		c.mut.Unlock()
+		return
+	}
+	y := c.h - 1
+	row := c.chars[y*c.w : (y+1)*c.w]
+	var x uint
+	for _, r := range truncate(StripColors(s), int(c.w)) {
+		switch runeWidth(r) {
+		case 0:
+			continue
+		case 2:
+			row[x] = ColorRune{fg, bgb, r, false, 2, 0}
+			row[x+1] = ColorRune{fg, bgb, 0, false, 1, 0}
+			x += 2
+			continue
+		}
+		row[x] = ColorRune{fg: fg, bg: bgb, r: r}
+		x++
+	}
+	for ; x < c.w; x++ {
+		row[x] = ColorRune{fg: fg, bg: bgb, r: ' '}
+	}
+	if len(c.oldchars) == len(c.chars) {
+		copy(c.oldchars[y*c.w:], row)
+	}
+	if linearOutput.Load() {
+		c.mut.Unlock()
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(beginSyncUpdate)
+	sb.WriteString(hideCursor)
+	fmt.Fprintf(&sb, "\033[%d;1H\033[0m", y+1)
+	writeCellColors(&sb, row[0])
+	for _, cr := range row[:c.w-1] {
+		if cr.cw != 1 {
+			sb.WriteRune(cr.r)
+		}
+	}
+	if last := row[c.w-1]; last.cw != 1 {
+		c.writeLastCell(&sb, last)
+	}
+	// Put the cursor back where it was
+	fmt.Fprintf(&sb, cursorHomeTemplate, c.termY+1, c.termX+1)
+	sb.WriteString(endSyncUpdate)
+	if c.termCursorVisible {
+		sb.WriteString(showCursor)
+	}
+	out := c.out
+	c.mut.Unlock()
+	writeOutput(out, sb.String())
+}
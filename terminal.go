@@ -1,10 +1,12 @@
 package vt
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/xyproto/env/v2"
 )
@@ -31,10 +33,16 @@ const (
 	disableLineWrap    = "\033[?7l"
 	showCursor         = "\033[?25h"
 	hideCursor         = "\033[?25l"
+	appCursorKeys      = "\033[?1h"
+	normalCursorKeys   = "\033[?1l"
 	echoOff            = "\033[12h"
 	attributeTemplate  = "\033[%sm"
 	beginSyncUpdate    = "\033[?2026h"
 	endSyncUpdate      = "\033[?2026l"
+	enablePaste        = "\033[?2004h"
+	disablePaste       = "\033[?2004l"
+	reverseScreen      = "\033[?5h"
+	normalScreen       = "\033[?5l"
 )
 
 // NoColor is the escape sequence for resetting all color attributes
@@ -139,14 +147,63 @@ func HasShiftArrows() bool {
 }
 
 // Has256Colors returns true when the terminal supports 256-color mode
-// (i.e. $TERM contains "256color" or is "xterm-kitty").
+// (i.e. $TERM contains "256color" or is "xterm-kitty", or the terminfo
+// entry for $TERM has at least 256 colors).
 func Has256Colors() bool {
 	term := env.Str("TERM")
-	return strings.Contains(term, "256color") || term == "xterm-kitty"
+	if strings.Contains(term, "256color") || term == "xterm-kitty" {
+		return true
+	}
+	colors, _ := TerminfoColors()
+	return colors >= 256
+}
+
+// ErrNotATerminal is returned by Init when stdout is not a terminal
+var ErrNotATerminal = errors.New("stdout is not a terminal")
+
+var (
+	// initMut protects initCount
+	initMut sync.Mutex
+	// initCount is the number of Init calls that have not yet been
+	// matched by a call to Close or CloseKeepContent
+	initCount int
+)
+
+// Initialized returns true if the terminal has been initialized with Init
+// or ForceInit, and not yet restored with Close or CloseKeepContent.
+// Libraries can use this to find out if the host application already
+// set up the terminal.
+func Initialized() bool {
+	initMut.Lock()
+	defer initMut.Unlock()
+	return initCount > 0
+}
+
+// Init initializes the terminal for full-screen canvas use.
+// Calls may be nested: only the first call configures the terminal, and
+// the terminal is only restored when the matching outermost Close is called.
+// If stdout is not a terminal, nothing is configured and ErrNotATerminal is
+// returned, unless the linear output mode is enabled, which works without
+// one. The pipes that mintty uses as a PTY on Windows, for instance in Git
+// Bash, count as a terminal. Use ForceInit to initialize anyway, for
+// example for dumb terminals.
+func Init() error {
+	if !linearOutput.Load() && !stdoutIsTerminal() {
+		return ErrNotATerminal
+	}
+	ForceInit()
+	return nil
 }
 
-// Init initializes the terminal for full-screen canvas use
-func Init() {
+// ForceInit is like Init, but initializes the terminal even if stdout is
+// not a terminal
+func ForceInit() {
+	initMut.Lock()
+	defer initMut.Unlock()
+	initCount++
+	if initCount > 1 || linearOutput.Load() {
+		return
+	}
 	initTerminal()
 	if safeReset {
 		Reset()   // \033c (RIS): only safe on xterm-class terminals outside multiplexers
@@ -157,17 +214,39 @@ func Init() {
 	SetLineWrap(false)
 }
 
+// release decrements the init counter and returns true if the terminal
+// should be restored. Unbalanced calls (without a matching Init) always
+// restore the terminal.
+func release() bool {
+	initMut.Lock()
+	defer initMut.Unlock()
+	if initCount > 1 {
+		initCount--
+		return false
+	}
+	initCount = 0
+	return true
+}
+
 // Close restores the terminal and clears the screen.
 // Use CloseKeepContent to keep the canvas content visible.
+// When Init calls are nested, only the outermost Close restores the terminal.
 func Close() {
+	if !release() || linearOutput.Load() {
+		return
+	}
 	SetLineWrap(true)
 	ShowCursor(true)
 	Clear()
 	Home()
 }
 
-// CloseKeepContent restores the terminal but leaves the canvas content visible
+// CloseKeepContent restores the terminal but leaves the canvas content visible.
+// When Init calls are nested, only the outermost call restores the terminal.
 func CloseKeepContent() {
+	if !release() || linearOutput.Load() {
+		return
+	}
 	SetLineWrap(true)
 	ShowCursor(true)
 	Home()
@@ -182,6 +261,7 @@ func EchoOff() {
 
 // SetLineWrap enables or disables line wrapping
 func SetLineWrap(enable bool) {
+	modes.lineWrapOff.Store(!enable)
 	if enable {
 		fmt.Print(enableLineWrap)
 	} else {
@@ -189,9 +269,36 @@ func SetLineWrap(enable bool) {
 	}
 }
 
+// SetApplicationCursorKeys enables or disables application cursor keys
+// (DECCKM). When enabled, the terminal sends SS3 sequences like "\x1bOA"
+// for the arrow keys, Home and End, instead of "\x1b[A". ReadKey and Key
+// understand both forms, so this is mainly useful for putting a terminal
+// that defaults to application mode back into normal mode.
+func SetApplicationCursorKeys(enable bool) {
+	modes.appCursorKeys.Store(enable)
+	if enable {
+		fmt.Print(appCursorKeys)
+	} else {
+		fmt.Print(normalCursorKeys)
+	}
+}
+
+// SetReverseScreen enables or disables reverse video for the whole screen
+// (DECSCNM), without changing any cells. Enabling it briefly and then
+// disabling it again gives a visual bell.
+func SetReverseScreen(enable bool) {
+	modes.reverseScreen.Store(enable)
+	if enable {
+		fmt.Print(reverseScreen)
+	} else {
+		fmt.Print(normalScreen)
+	}
+}
+
 // ShowCursor shows or hides the terminal cursor
 func ShowCursor(enable bool) {
 	showCursorHelper(enable)
+	modes.cursorHidden.Store(!enable)
 	if enable {
 		fmt.Print(showCursor)
 	} else {
@@ -243,6 +350,44 @@ func GetBackgroundColor(tty *TTY) (float64, float64, float64, error) {
 	return 0, 0, 0, fmt.Errorf("could not read rgb value from terminal emulator, got: %q", result)
 }
 
+// DetectLightBackground reports whether the terminal has a light
+// background. The COLORFGBG environment variable, which many terminals set
+// to for instance "15;0" for a light foreground on a dark background, is
+// checked first, since it does not require a round trip to the terminal.
+// If it is not set, or if it gives the background as "default", the
+// terminal is asked with GetBackgroundColor, unless tty is nil. ok is false
+// if the background could not be detected.
+func DetectLightBackground(tty *TTY) (light, ok bool) {
+	if light, ok := parseColorFGBG(env.Str("COLORFGBG")); ok {
+		return light, true
+	}
+	if tty == nil {
+		return false, false
+	}
+	r, g, b, err := GetBackgroundColor(tty)
+	if err != nil {
+		return false, false
+	}
+	return 0.299*r+0.587*g+0.114*b > 0.5, true
+}
+
+// parseColorFGBG returns true if the background in a COLORFGBG value is
+// one of the light colors of the 16 color palette: light gray (7) or one
+// of the bright colors other than dark gray (9 to 15). ok is false if the
+// value is missing, malformed or "default".
+func parseColorFGBG(s string) (light, ok bool) {
+	if s == "" {
+		return false, false
+	}
+	// The background is the last field, also in the "fg;default;bg" form
+	fields := strings.Split(s, ";")
+	bg, err := strconv.Atoi(strings.TrimSpace(fields[len(fields)-1]))
+	if len(fields) < 2 || err != nil || bg < 0 || bg > 15 {
+		return false, false
+	}
+	return bg == 7 || bg > 8, true
+}
+
 // BeginSyncUpdate sends the terminal's begin synchronized update escape sequence
 func BeginSyncUpdate() {
 	fmt.Print(beginSyncUpdate)
@@ -0,0 +1,200 @@
+package vt
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xyproto/env/v2"
+)
+
+func TestInitNesting(t *testing.T) {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer devNull.Close()
+	stdout := os.Stdout
+	os.Stdout = devNull
+	defer func() { os.Stdout = stdout }()
+
+	if err := Init(); err != ErrNotATerminal {
+		t.Fatalf("Init on a non-terminal: got %v, want ErrNotATerminal", err)
+	}
+	if Initialized() {
+		t.Fatal("Initialized after a failed Init")
+	}
+	ForceInit()
+	ForceInit()
+	Close()
+	if !Initialized() {
+		t.Error("inner Close should not restore the terminal")
+	}
+	Close()
+	if Initialized() {
+		t.Error("outer Close should restore the terminal")
+	}
+
+	// The linear output mode does not need a terminal
+	defer SetLinearOutput(LinearOutput())
+	SetLinearOutput(true)
+	if err := Init(); err != nil {
+		t.Errorf("Init with linear output: got %v, want nil", err)
+	}
+	Close()
+}
+
+func TestDebugStateTracksModes(t *testing.T) {
+	defer discardStdout(t)()
+	defer SetBracketedPaste(false)
+	defer ShowCursor(true)
+
+	SetBracketedPaste(true)
+	ShowCursor(false)
+	info := DebugState(NewTTYFromReader(strings.NewReader("")))
+	if !info.BracketedPaste || info.CursorVisible {
+		t.Errorf("the modes were not tracked: %+v", info)
+	}
+	if info.StdoutTerminal {
+		t.Error("stdout is the null device, not a terminal")
+	}
+	if info.ReadTimeout != defaultTimeout || !info.EscapeTimeoutAdaptive {
+		t.Errorf("unexpected timeouts: %+v", info)
+	}
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"bracketed_paste":true`) {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+}
+
+func TestMaxCanvasSize(t *testing.T) {
+	defer discardStdout(t)()
+	defer SetMaxCanvasSize(1000, 1000)
+	t.Cleanup(env.Load) // runs after the variables below have been restored
+	t.Setenv("COLS", "")
+	t.Setenv("COLUMNS", "100000")
+	t.Setenv("LINES", "-1")
+	env.Load()
+	if w, h := MustTermSize(); w != 1000 || h != 1000 {
+		t.Errorf("got %dx%d, want the default maximum of 1000x1000", w, h)
+	}
+	SetMaxCanvasSize(120, 40)
+	if c := NewCanvas(); c.W() != 120 || c.H() != 40 {
+		t.Errorf("got a %dx%d canvas, want 120x40", c.W(), c.H())
+	}
+}
+
+func TestDetectLightBackground(t *testing.T) {
+	for _, tc := range []struct {
+		value     string
+		light, ok bool
+	}{
+		{"15;0", false, true},
+		{"0;15", true, true},
+		{"0;7", true, true},
+		{"15;8", false, true},
+		{"0;default;15", true, true},
+		{"15;default", false, false},
+		{"", false, false},
+		{"15", false, false},
+		{"0;99", false, false},
+	} {
+		if light, ok := parseColorFGBG(tc.value); light != tc.light || ok != tc.ok {
+			t.Errorf("parseColorFGBG(%q) = (%v, %v), want (%v, %v)", tc.value, light, ok, tc.light, tc.ok)
+		}
+	}
+
+	t.Cleanup(env.Load) // runs after the variable below has been restored
+	t.Setenv("COLORFGBG", "0;15")
+	env.Load()
+	if light, ok := DetectLightBackground(nil); !light || !ok {
+		t.Errorf("got (%v, %v), want a light background from COLORFGBG", light, ok)
+	}
+	t.Setenv("COLORFGBG", "")
+	env.Load()
+	if _, ok := DetectLightBackground(nil); ok {
+		t.Error("expected no answer without COLORFGBG and without a TTY")
+	}
+}
+
+// compiledTerminfo returns a minimal compiled terminfo entry with the given
+// number of colors, in the 16-bit or the 32-bit format
+func compiledTerminfo(colors int, wide bool) []byte {
+	magic, numSize := uint16(terminfoMagic16), 2
+	if wide {
+		magic, numSize = terminfoMagic32, 4
+	}
+	data := binary.LittleEndian.AppendUint16(nil, magic)
+	for _, n := range []uint16{4, 1, 14, 0, 0} { // names, bools, numbers, strings, string table
+		data = binary.LittleEndian.AppendUint16(data, n)
+	}
+	data = append(data, "t|x\x00"...)
+	data = append(data, 1, 0) // a boolean, and padding to an even offset
+	for i := range 14 {
+		n := -1
+		if i == terminfoColorsIndex {
+			n = colors
+		}
+		if numSize == 4 {
+			data = binary.LittleEndian.AppendUint32(data, uint32(int32(n)))
+		} else {
+			data = binary.LittleEndian.AppendUint16(data, uint16(int16(n)))
+		}
+	}
+	return data
+}
+
+func TestTerminfoColors(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "t"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "t", "test-256"), compiledTerminfo(256, false), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "t", "test-direct"), compiledTerminfo(1<<24, true), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "t", "test-broken"), []byte("nope"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(env.Load) // runs after the variable below has been restored
+	t.Setenv("TERMINFO", dir)
+	env.Load()
+	for term, want := range map[string]int{"test-256": 256, "test-direct": 1 << 24, "test-broken": 0, "test-missing": 0, "../t": 0} {
+		if got, ok := terminfoColors(term); got != want || ok != (want > 0) {
+			t.Errorf("terminfoColors(%q) = (%d, %v), want %d", term, got, ok, want)
+		}
+	}
+}
+
+func TestSetReverseScreen(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer f.Close()
+	stdout := os.Stdout
+	os.Stdout = f
+	SetReverseScreen(true)
+	on := modes.reverseScreen.Load()
+	SetReverseScreen(false)
+	os.Stdout = stdout
+
+	if !on || modes.reverseScreen.Load() {
+		t.Errorf("the mode was not tracked: got %v after enabling and %v after disabling", on, modes.reverseScreen.Load())
+	}
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "\033[?5h\033[?5l"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,115 @@
+package vt
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/xyproto/env/v2"
+)
+
+// Magic numbers of compiled terminfo files, with 16-bit and 32-bit numbers
+const (
+	terminfoMagic16 = 0o432
+	terminfoMagic32 = 0o1036
+)
+
+// terminfoColorsIndex is the index of the "colors" capability among the
+// numeric capabilities of a compiled terminfo file
+const terminfoColorsIndex = 13
+
+// terminfoDirs returns the directories that are searched for compiled
+// terminfo files, in the same order as ncurses
+func terminfoDirs() []string {
+	var dirs []string
+	if dir := env.Str("TERMINFO"); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".terminfo"))
+	}
+	for dir := range strings.SplitSeq(env.Str("TERMINFO_DIRS"), ":") {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return append(dirs, "/etc/terminfo", "/lib/terminfo", "/usr/share/terminfo")
+}
+
+// terminfoColors returns the "colors" capability of the compiled terminfo
+// entry for term, or false if the entry can not be found or read
+func terminfoColors(term string) (int, bool) {
+	if term == "" || strings.ContainsAny(term, "/\\") {
+		return 0, false
+	}
+	for _, dir := range terminfoDirs() {
+		// Most systems use the first letter as the subdirectory, macOS uses
+		// its hexadecimal value
+		for _, sub := range []string{term[:1], strconv.FormatInt(int64(term[0]), 16)} {
+			data, err := os.ReadFile(filepath.Join(dir, sub, term))
+			if err != nil {
+				continue
+			}
+			colors, err := parseTerminfoColors(data)
+			if err != nil {
+				return 0, false
+			}
+			return colors, colors > 0
+		}
+	}
+	return 0, false
+}
+
+// parseTerminfoColors reads the "colors" capability from a compiled
+// terminfo file. 0 is returned if the capability is absent.
+func parseTerminfoColors(data []byte) (int, error) {
+	errInvalid := errors.New("invalid terminfo file")
+	if len(data) < 12 {
+		return 0, errInvalid
+	}
+	header := func(i int) int { return int(int16(binary.LittleEndian.Uint16(data[i*2:]))) }
+	numSize := 2
+	switch header(0) {
+	case terminfoMagic16:
+	case terminfoMagic32:
+		numSize = 4
+	default:
+		return 0, errInvalid
+	}
+	namesSize, boolCount, numCount := header(1), header(2), header(3)
+	if namesSize < 0 || boolCount < 0 || numCount < 0 {
+		return 0, errInvalid
+	}
+	offset := 12 + namesSize + boolCount
+	offset += offset % 2 // the numbers start at an even offset
+	if numCount <= terminfoColorsIndex {
+		return 0, nil
+	}
+	start := offset + terminfoColorsIndex*numSize
+	if start+numSize > len(data) {
+		return 0, errInvalid
+	}
+	var colors int
+	if numSize == 4 {
+		colors = int(int32(binary.LittleEndian.Uint32(data[start:])))
+	} else {
+		colors = int(int16(binary.LittleEndian.Uint16(data[start:])))
+	}
+	return max(colors, 0), nil // negative values mean absent or cancelled
+}
+
+// terminfoColorsOnce looks up the colors of $TERM once
+var terminfoColorsOnce = sync.OnceValues(func() (int, bool) {
+	return terminfoColors(env.Str("TERM"))
+})
+
+// TerminfoColors returns the number of colors the terminal supports,
+// according to the terminfo database entry for $TERM. ok is false if no
+// entry was found. The lookup is only done once.
+func TerminfoColors() (colors int, ok bool) {
+	return terminfoColorsOnce()
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"github.com/xyproto/env/v2"
 )
@@ -18,10 +19,8 @@ type CharAttribute struct {
 
 // TextOutput keeps state about verbosity and if colors are enabled
 type TextOutput struct {
-	lightReplacer *strings.Replacer
-	darkReplacer  *strings.Replacer
-	color         bool
-	enabled       bool
+	color   bool
+	enabled bool
 }
 
 // EnvNoColor respects the NO_COLOR environment variable
@@ -35,9 +34,7 @@ func NewTextOutput(color, enabled bool) *TextOutput {
 	if EnvNoColor {
 		color = false
 	}
-	o := &TextOutput{nil, nil, color, enabled}
-	o.initializeTagReplacers()
-	return o
+	return &TextOutput{color, enabled}
 }
 
 // DisableColors will enable color output
@@ -55,9 +52,7 @@ func (o *TextOutput) DisableColors() {
 // output can be enabled (verbose) or disabled (silent).
 // If NO_COLOR is set, colors are disabled.
 func New() *TextOutput {
-	o := &TextOutput{nil, nil, !EnvNoColor, true}
-	o.initializeTagReplacers()
-	return o
+	return &TextOutput{!EnvNoColor, true}
 }
 
 // OutputTags will output text that may have tags like "<blue>", "</blue>" or "<off>" for
@@ -184,7 +179,7 @@ func (o *TextOutput) LightBlue(s string) string {
 // Replace <blue> with starting a light blue color attribute and <off> with using the default attributes.
 // </blue> can also be used for using the default attributes.
 func (o *TextOutput) LightTags(colors ...string) string {
-	return o.lightReplacer.Replace(strings.Join(colors, ""))
+	return o.lightTagReplacer().Replace(strings.Join(colors, ""))
 }
 
 // Same as LightTags
@@ -208,81 +203,138 @@ func (o *TextOutput) InterfaceTags(colors ...any) string {
 // Replace <blue> with starting a light blue color attribute and <off> with using the default attributes.
 // </blue> can also be used for using the default attributes.
 func (o *TextOutput) DarkTags(colors ...string) string {
-	return o.darkReplacer.Replace(strings.Join(colors, ""))
+	return o.darkTagReplacer().Replace(strings.Join(colors, ""))
 }
 
-// buildTagReplacer builds a strings.Replacer that substitutes <color>/</color>
-// HTML-like tags in text. Each key in colorMap generates four pairs covering
-// both <key>/</key> and <Key>/</Key>. When enabled is false every tag is
-// replaced with an empty string (strip-only mode).
-func buildTagReplacer(colorMap map[string]AttributeColor, enabled bool) *strings.Replacer {
-	off := NoColor
-	rs := make([]string, len(colorMap)*8+2)
-	i := 0
-	for key, value := range colorMap {
-		titled := strings.ToUpper(key[:1]) + key[1:]
-		var esc, reset string
+// tagAliases are short tags that are recognized in addition to the color
+// names, for instance <b>bold</b>
+var tagAliases = map[string]AttributeColor{
+	"b": Bold,
+	"i": Italic,
+	"u": Underscore,
+}
+
+// maxTagLength is the length of the longest tag that is looked up, to avoid
+// scanning far ahead for a closing '>' after a lone '<'
+const maxTagLength = 64
+
+// tagReplacer substitutes <color>/</color> HTML-like tags in text. Tags are
+// matched case-insensitively, and text that is not a known tag is left as
+// it is.
+type tagReplacer struct {
+	tags map[string]string // replacements, by lowercase tag
+}
+
+// buildTagReplacer builds a tagReplacer for the names in colorMap and for
+// the aliases in tagAliases. Each name generates both <name> and </name>,
+// and <off> and </> reset the attributes. When enabled is false every tag
+// is replaced with an empty string (strip-only mode).
+func buildTagReplacer(colorMap map[string]AttributeColor, enabled bool) *tagReplacer {
+	tr := &tagReplacer{tags: make(map[string]string, (len(colorMap)+len(tagAliases))*2+2)}
+	var reset string
+	if enabled {
+		reset = NoColor
+	}
+	add := func(key string, value AttributeColor) {
+		key = strings.ToLower(key)
+		var esc string
 		if enabled {
 			esc = value.String()
-			reset = off
 		}
-		rs[i] = "<" + key + ">"
-		rs[i+1] = esc
-		rs[i+2] = "</" + key + ">"
-		rs[i+3] = reset
-		rs[i+4] = "<" + titled + ">"
-		rs[i+5] = esc
-		rs[i+6] = "</" + titled + ">"
-		rs[i+7] = reset
-		i += 8
+		tr.tags["<"+key+">"] = esc
+		tr.tags["</"+key+">"] = reset
 	}
-	if enabled {
-		rs[i] = "<off>"
-		rs[i+1] = off
-	} else {
-		rs[i] = "<off>"
-		rs[i+1] = ""
+	for key, value := range tagAliases {
+		add(key, value)
 	}
-	return strings.NewReplacer(rs...)
+	for key, value := range colorMap {
+		add(key, value)
+	}
+	tr.tags["<off>"] = reset
+	tr.tags["</>"] = reset
+	return tr
+}
+
+// Replace returns s with all known tags replaced
+func (tr *tagReplacer) Replace(s string) string {
+	i := strings.IndexByte(s, '<')
+	if i < 0 {
+		return s
+	}
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for i >= 0 {
+		sb.WriteString(s[:i])
+		s = s[i:]
+		j := strings.IndexByte(s[1:], '>') + 1
+		if j > 0 && j < maxTagLength && !strings.Contains(s[1:j], "<") {
+			if replacement, ok := tr.tags[strings.ToLower(s[:j+1])]; ok {
+				sb.WriteString(replacement)
+				s = s[j+1:]
+				i = strings.IndexByte(s, '<')
+				continue
+			}
+		}
+		sb.WriteByte('<')
+		s = s[1:]
+		i = strings.IndexByte(s, '<')
+	}
+	sb.WriteString(s)
+	return sb.String()
 }
 
 // Tag replacers are built once at package init and shared across all TextOutput
 // instances; building them is O(|colorMap|) and involves string allocations, so
-// doing it once avoids repeated work on every New() call.
+// doing it once avoids repeated work on every New() call. They are stored
+// atomically, since they are rebuilt when color dictionaries are registered.
 var (
-	cachedLightOnReplacer  *strings.Replacer
-	cachedLightOffReplacer *strings.Replacer
-	cachedDarkOnReplacer   *strings.Replacer
-	cachedDarkOffReplacer  *strings.Replacer
+	cachedLightOnReplacer  atomic.Pointer[tagReplacer]
+	cachedLightOffReplacer atomic.Pointer[tagReplacer]
+	cachedDarkOnReplacer   atomic.Pointer[tagReplacer]
+	cachedDarkOffReplacer  atomic.Pointer[tagReplacer]
 )
 
 func init() {
-	cachedLightOnReplacer = buildTagReplacer(LightColorMap, true)
-	cachedLightOffReplacer = buildTagReplacer(LightColorMap, false)
-	cachedDarkOnReplacer = buildTagReplacer(DarkColorMap, true)
-	cachedDarkOffReplacer = buildTagReplacer(DarkColorMap, false)
+	RebuildTagReplacers()
 }
 
 // RebuildTagReplacers rebuilds the cached tag replacers from the current
-// DarkColorMap and LightColorMap. Call this after adding entries to either map
-// so that the new entries are recognized by DarkTags and LightTags.
+// DarkColorMap and LightColorMap, and from the registered color dictionaries.
+// Call this after adding entries to either map so that the new entries are
+// recognized by DarkTags and LightTags.
 func RebuildTagReplacers() {
-	cachedLightOnReplacer = buildTagReplacer(LightColorMap, true)
-	cachedLightOffReplacer = buildTagReplacer(LightColorMap, false)
-	cachedDarkOnReplacer = buildTagReplacer(DarkColorMap, true)
-	cachedDarkOffReplacer = buildTagReplacer(DarkColorMap, false)
+	dictMut.Lock()
+	defer dictMut.Unlock()
+	rebuildTagReplacers()
 }
 
-// initializeTagReplacers assigns pre-built singleton replacers to this
-// TextOutput based on whether colors are enabled.
-func (o *TextOutput) initializeTagReplacers() {
+// rebuildTagReplacers must be called with dictMut held
+func rebuildTagReplacers() {
+	light := withDictionaries(LightColorMap)
+	dark := withDictionaries(DarkColorMap)
+	cachedLightOnReplacer.Store(buildTagReplacer(light, true))
+	cachedLightOffReplacer.Store(buildTagReplacer(light, false))
+	cachedDarkOnReplacer.Store(buildTagReplacer(dark, true))
+	cachedDarkOffReplacer.Store(buildTagReplacer(dark, false))
+}
+
+// lightTagReplacer returns the shared light tag replacer, depending on
+// whether colors are enabled. It is looked up each time tags are expanded,
+// so that color dictionaries registered after the TextOutput was created
+// are recognized too.
+func (o *TextOutput) lightTagReplacer() *tagReplacer {
+	if o.color {
+		return cachedLightOnReplacer.Load()
+	}
+	return cachedLightOffReplacer.Load()
+}
+
+// darkTagReplacer is the DarkColorMap variant of lightTagReplacer
+func (o *TextOutput) darkTagReplacer() *tagReplacer {
 	if o.color {
-		o.lightReplacer = cachedLightOnReplacer
-		o.darkReplacer = cachedDarkOnReplacer
-	} else {
-		o.lightReplacer = cachedLightOffReplacer
-		o.darkReplacer = cachedDarkOffReplacer
+		return cachedDarkOnReplacer.Load()
 	}
+	return cachedDarkOffReplacer.Load()
 }
 
 // ExtractToSlice iterates over an ANSI encoded string, parsing out color codes and places it in
@@ -357,3 +409,52 @@ func (o *TextOutput) ExtractToSlice(s string, pcc *[]CharAttribute) uint {
 	}
 	return counter
 }
+
+// StripColors removes the escape sequences from s, and returns the plain
+// text. CSI sequences, like the SGR sequences emitted by AttributeColor and
+// by the tags of Println, and OSC sequences, like hyperlinks and window
+// titles, are removed, as are other two-byte escape sequences. This is
+// useful for writing colored text to log files.
+func StripColors(s string) string {
+	if !strings.Contains(s, "\033") {
+		return s
+	}
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\033' {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i = escapeEnd(s, i) - 1
+	}
+	return sb.String()
+}
+
+// escapeEnd returns the index just past the escape sequence that starts
+// with the ESC at s[i]. An unterminated sequence runs to the end of s.
+func escapeEnd(s string, i int) int {
+	if i+1 >= len(s) {
+		return len(s)
+	}
+	switch s[i+1] {
+	case '[':
+		// CSI: parameter and intermediate bytes, then a final byte
+		j := i + 2
+		for j < len(s) && (s[j] < 0x40 || s[j] > 0x7E) {
+			j++
+		}
+		return min(j+1, len(s))
+	case ']':
+		// OSC: terminated by BEL or by ESC \
+		j := i + 2
+		for j < len(s) && s[j] != '\a' && !(s[j] == '\033' && j+1 < len(s) && s[j+1] == '\\') {
+			j++
+		}
+		if j < len(s) && s[j] == '\033' {
+			j++
+		}
+		return min(j+1, len(s))
+	}
+	return i + 2
+}
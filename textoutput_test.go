@@ -1,6 +1,11 @@
 package vt
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
 
 func ExamplePrintln() {
 	o := NewTextOutput(true, true)
@@ -69,3 +74,138 @@ func TestNoColorRespected(t *testing.T) {
 		t.Errorf("BestBackground() = %v, want DefaultBackground when NO_COLOR is set", got)
 	}
 }
+
+func TestTagsCaseInsensitiveAndAliases(t *testing.T) {
+	if EnvNoColor {
+		t.Skip("NO_COLOR is set")
+	}
+	o := NewTextOutput(true, true)
+	want := LightRed.String() + "a" + NoColor + Bold.String() + "b" + NoColor
+	if got := o.LightTags("<RED>a</Red><b>b</>"); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	for _, s := range []string{"a < b > c", "<unknown>", "<<red", "x <"} {
+		if got := o.LightTags(s); got != s {
+			t.Errorf("expected %q to pass through, got %q", s, got)
+		}
+	}
+	if got := NewTextOutput(false, true).LightTags("<I>x</i><U>y</u>"); got != "xy" {
+		t.Errorf("expected the tags to be stripped, got %q", got)
+	}
+}
+
+func TestStatusLine(t *testing.T) {
+	var sb strings.Builder
+	o := NewTextOutput(false, true)
+	o.statusLine(&sb, LightGreen, "✓", "[OK]", "done")
+	want := "[OK] done\n"
+	if utf8Locale {
+		want = "✓ done\n"
+	}
+	if got := sb.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	sb.Reset()
+	o.Disable()
+	o.statusLine(&sb, LightRed, "✗", "[ERR]", "failed")
+	if sb.Len() != 0 {
+		t.Errorf("expected no output when output is disabled, got %q", sb.String())
+	}
+}
+
+func TestStripColors(t *testing.T) {
+	o := NewTextOutput(true, true)
+	for _, tc := range []struct{ in, want string }{
+		{"plain", "plain"},
+		{Red.Get("red"), "red"},
+		{"\x1b[38;2;1;2;3mtrue\x1b[0m color", "true color"},
+		{Bold.Combine(Blue).Combine(BackgroundYellow).Get("x"), "x"},
+		{o.Tags("<red>a</red><b>b</b>"), "ab"},
+		{"\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\", "link"},
+		{"\x1b]0;title\atext", "text"},
+		{"\x1b[2J\x1b[1;1Hhome", "home"},
+		{"日本\x1b[31m語", "日本語"},
+		{"cut\x1b[38;5", "cut"},
+	} {
+		if got := StripColors(tc.in); got != tc.want {
+			t.Errorf("StripColors(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestVisibleWidth(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"日本", 4},
+		{Red.Get("ok") + " 日", 5},
+		{"é", 1}, // e with a combining acute accent
+		{NewTextOutput(true, true).Tags("<blue>hi</blue>"), 2},
+	} {
+		if got := VisibleWidth(tc.s); got != tc.want {
+			t.Errorf("VisibleWidth(%q) = %d, want %d", tc.s, got, tc.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	for _, tc := range []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"hello", 5, "hello"},
+		{"hello", 4, "hel…"},
+		{"日本語", 6, "日本語"},
+		{"日本語", 5, "日本…"},
+		{"日本語", 4, "日…"},
+		{"hello", 0, ""},
+	} {
+		if got := truncate(tc.s, tc.width); got != tc.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tc.s, tc.width, got, tc.want)
+		}
+	}
+}
+
+func TestStatusASCII(t *testing.T) {
+	defer func(saved bool) { utf8Locale = saved }(utf8Locale)
+	utf8Locale = false
+
+	dir := t.TempDir()
+	stdoutFile, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Skip(err)
+	}
+	defer stdoutFile.Close()
+	stderrFile, err := os.Create(filepath.Join(dir, "stderr"))
+	if err != nil {
+		t.Skip(err)
+	}
+	defer stderrFile.Close()
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = stdoutFile, stderrFile
+	o := NewTextOutput(false, true)
+	o.Success("built")
+	o.Warn("slow")
+	o.Fail("broken")
+	NewTextOutput(false, false).Fail("silent")
+	os.Stdout, os.Stderr = stdout, stderr
+
+	for _, tc := range []struct {
+		name, want string
+	}{
+		{"stdout", "[OK] built\n"},
+		{"stderr", "[WARN] slow\n[ERR] broken\n"},
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, tc.name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(data); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package vt
+
+// FlipHorizontal mirrors the canvas contents left to right, in place.
+// Wide runes are kept intact, with the continuation cell after the base cell.
+func (c *Canvas) FlipHorizontal() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.flipHorizontalNoLock()
+}
+
+// FlipVertical mirrors the canvas contents top to bottom, in place
+func (c *Canvas) FlipVertical() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.flipVerticalNoLock()
+}
+
+// Rotate180 rotates the canvas contents by 180 degrees, in place
+func (c *Canvas) Rotate180() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.flipHorizontalNoLock()
+	c.flipVerticalNoLock()
+}
+
+// flipHorizontalNoLock reverses every row. Reversing turns a wide rune
+// (base, continuation) into (continuation, base), so each such pair is
+// swapped back afterwards.
+func (c *Canvas) flipHorizontalNoLock() {
+	w := c.w
+	for y := uint(0); y < c.h; y++ {
+		row := c.chars[y*w : (y+1)*w]
+		for i, j := 0, len(row)-1; i < j; i, j = i+1, j-1 {
+			row[i], row[j] = row[j], row[i]
+		}
+		for x := 0; x+1 < len(row); x++ {
+			if row[x].cw == 1 && row[x+1].cw == 2 {
+				row[x], row[x+1] = row[x+1], row[x]
+				x++
+			}
+		}
+		for x := range row {
+			row[x].drawn = false
+		}
+	}
+}
+
+// flipVerticalNoLock swaps the rows from top to bottom
+func (c *Canvas) flipVerticalNoLock() {
+	w := c.w
+	for top, bottom := uint(0), c.h-1; top < bottom && bottom < c.h; top, bottom = top+1, bottom-1 {
+		a := c.chars[top*w : (top+1)*w]
+		b := c.chars[bottom*w : (bottom+1)*w]
+		for x := range a {
+			a[x], b[x] = b[x], a[x]
+		}
+	}
+	for i := range c.chars {
+		c.chars[i].drawn = false
+	}
+}
+
+// blankCell is an empty cell with the default colors
+var blankCell = ColorRune{fg: Default, bg: DefaultBackground}
+
+// ScrollUp moves the canvas contents up by n rows, in place. The rows at
+// the bottom are blanked with the default colors.
+func (c *Canvas) ScrollUp(n uint) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	n = umin(n, c.h)
+	copy(c.chars, c.chars[n*c.w:])
+	c.blankAndMarkNoLock(c.chars[(c.h-n)*c.w:])
+}
+
+// ScrollDown moves the canvas contents down by n rows, in place. The rows
+// at the top are blanked with the default colors.
+func (c *Canvas) ScrollDown(n uint) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	n = umin(n, c.h)
+	copy(c.chars[n*c.w:], c.chars)
+	c.blankAndMarkNoLock(c.chars[:n*c.w])
+}
+
+// ScrollLeft moves the canvas contents left by n columns, in place. The
+// columns at the right are blanked with the default colors, and so is the
+// half of a wide rune that is cut at the left edge.
+func (c *Canvas) ScrollLeft(n uint) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	n = umin(n, c.w)
+	for y := range c.h {
+		row := c.chars[y*c.w : (y+1)*c.w]
+		copy(row, row[n:])
+		for x := c.w - n; x < c.w; x++ {
+			row[x] = blankCell
+		}
+		if len(row) > 0 && row[0].cw == 1 {
+			row[0] = blankCell
+		}
+	}
+	c.blankAndMarkNoLock(nil)
+}
+
+// ScrollRight moves the canvas contents right by n columns, in place. The
+// columns at the left are blanked with the default colors, and so is the
+// half of a wide rune that is cut at the right edge.
+func (c *Canvas) ScrollRight(n uint) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	n = umin(n, c.w)
+	for y := range c.h {
+		row := c.chars[y*c.w : (y+1)*c.w]
+		copy(row[n:], row)
+		for x := range n {
+			row[x] = blankCell
+		}
+		if last := len(row) - 1; last >= 0 && row[last].cw == 2 {
+			row[last] = blankCell
+		}
+	}
+	c.blankAndMarkNoLock(nil)
+}
+
+// blankAndMarkNoLock blanks the given cells and marks all cells as undrawn
+func (c *Canvas) blankAndMarkNoLock(cells []ColorRune) {
+	for i := range cells {
+		cells[i] = blankCell
+	}
+	for i := range c.chars {
+		c.chars[i].drawn = false
+	}
+}
@@ -0,0 +1,95 @@
+package vt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFlipHorizontal(t *testing.T) {
+	c := NewCanvasWithSize(5, 1)
+	c.WriteString(0, 0, Default, Default, "ab")
+	c.WriteWideRuneB(2, 0, Default, DefaultBackground, '日')
+	c.FlipHorizontal()
+	if r, _ := c.At(0, 0); r != 0 {
+		t.Errorf("expected empty cell at 0, got %q", r)
+	}
+	if c.chars[1].r != '日' || c.chars[1].cw != 2 || c.chars[2].cw != 1 {
+		t.Errorf("wide rune not kept intact: %+v %+v", c.chars[1], c.chars[2])
+	}
+	if c.chars[3].r != 'b' || c.chars[4].r != 'a' {
+		t.Errorf("got %q", c.String())
+	}
+}
+
+func TestFlipVerticalAndRotate180(t *testing.T) {
+	c := NewCanvasWithSize(2, 3)
+	c.WriteString(0, 0, Default, Default, "ab")
+	c.WriteString(0, 2, Default, Default, "cd")
+	c.FlipVertical()
+	if got, want := c.String(), "cd\n  \nab\n"; got != want {
+		t.Errorf("FlipVertical: got %q, want %q", got, want)
+	}
+	c.Rotate180()
+	if got, want := c.String(), "ba\n  \ndc\n"; got != want {
+		t.Errorf("Rotate180: got %q, want %q", got, want)
+	}
+}
+
+func TestScrollUpAndDown(t *testing.T) {
+	c := NewCanvasWithSize(2, 3)
+	c.WriteString(0, 0, Red, Default, "ab")
+	c.WriteString(0, 1, Red, Default, "cd")
+	c.WriteString(0, 2, Red, Default, "ef")
+	c.ScrollUp(1)
+	if got, want := c.String(), "cd\nef\n  \n"; got != want {
+		t.Errorf("ScrollUp: got %q, want %q", got, want)
+	}
+	if cr := c.chars[5]; cr != blankCell {
+		t.Errorf("expected a blank cell with the default colors, got %+v", cr)
+	}
+	c.ScrollDown(2)
+	if got, want := c.String(), "  \n  \ncd\n"; got != want {
+		t.Errorf("ScrollDown: got %q, want %q", got, want)
+	}
+	c.ScrollUp(10)
+	if got, want := c.String(), "  \n  \n  \n"; got != want {
+		t.Errorf("ScrollUp past the height: got %q, want %q", got, want)
+	}
+}
+
+func TestScrollLeftAndRight(t *testing.T) {
+	c := NewCanvasWithSize(5, 1)
+	c.WriteWideRuneB(0, 0, Default, DefaultBackground, '日')
+	c.WriteString(2, 0, Default, Default, "abc")
+	c.ScrollLeft(1)
+	if c.chars[0] != blankCell || c.chars[1].r != 'a' || c.chars[4] != blankCell {
+		t.Errorf("expected the cut wide rune to be blanked: %+v", c.chars)
+	}
+	c.WriteWideRuneB(3, 0, Default, DefaultBackground, '日')
+	c.ScrollRight(1)
+	if c.chars[0] != blankCell || c.chars[2].r != 'a' || c.chars[4] != blankCell {
+		t.Errorf("expected the cut wide rune to be blanked: %+v", c.chars)
+	}
+}
+
+func TestScrollUpKeepsWideRunesAndDrawDiff(t *testing.T) {
+	c := NewCanvasWithSize(4, 3)
+	var buf strings.Builder
+	c.SetOutput(&buf)
+	c.WriteString(0, 0, Default, DefaultBackground, "log1")
+	c.WriteString(0, 1, Default, DefaultBackground, "日本")
+	c.Draw()
+
+	buf.Reset()
+	c.ScrollUp(1)
+	if row := c.Row(0); row[0].r != '日' || row[0].cw != 2 || row[1].cw != 1 || row[2].cw != 2 || row[3].cw != 1 {
+		t.Errorf("expected the wide runes to move as a whole: %+v", row)
+	}
+	c.WriteString(0, 2, Default, DefaultBackground, "log3")
+	if !c.Draw() {
+		t.Fatal("expected the scrolled rows to be drawn")
+	}
+	if out := buf.String(); !strings.Contains(out, "日本") || !strings.Contains(out, "log") {
+		t.Errorf("got %q", out)
+	}
+}
@@ -10,22 +10,28 @@ import (
 // (i.e. holds a 24-bit RGB value)
 func IsTrueColor(ac AttributeColor) bool {
 	val := uint32(ac)
-	return val&extendedFlag != 0 && val&trueColorFlag != 0
+	return !ac.isPair() && val&extendedFlag != 0 && val&trueColorFlag != 0
 }
 
 // Is256Color reports whether ac was created with Color256 or Background256
 // (i.e. holds an xterm-256color palette index)
 func Is256Color(ac AttributeColor) bool {
 	val := uint32(ac)
-	return val&extendedFlag != 0 && val&trueColorFlag == 0
+	return !ac.isPair() && val&extendedFlag != 0 && val&trueColorFlag == 0
 }
 
 // ToRGB extracts the RGB components of any AttributeColor:
 //   - TrueColor / TrueBackground → exact 24-bit values, ok=true
 //   - 256-color / background256  → palette-derived values via Color256ToRGB, ok=true
 //   - Standard ANSI 16 foreground (30–37, 90–97) → approximate values from ansi16Palette, ok=true
+//   - Two extended values combined with Combine → the values of the first one
 //   - Anything else (attributes, Default, …)      → 0, 0, 0, false
 func ToRGB(ac AttributeColor) (r, g, b uint8, ok bool) {
+	if ac.isPair() {
+		// A pair combined with Combine: use the first value
+		first, _ := ac.pair()
+		return ToRGB(first)
+	}
 	val := uint32(ac)
 	if val&extendedFlag != 0 {
 		if val&trueColorFlag != 0 {
@@ -55,6 +61,11 @@ func ToHex(ac AttributeColor) string {
 	return fmt.Sprintf("#%02x%02x%02x", r, g, b)
 }
 
+// Hex returns the color as a "#rrggbb" string, like ToHex
+func (ac AttributeColor) Hex() string {
+	return ToHex(ac)
+}
+
 // clampF converts a [0.0, 1.0] float64 to a uint8, clamping at the boundaries
 func clampF(v float64) uint8 {
 	if v <= 0 {
@@ -190,6 +201,19 @@ func BestColorFromHex(s string) AttributeColor {
 	return BestColor(r, g, b)
 }
 
+// ParseHex parses a hex color string, like "#3A7BD5", "#f00" or
+// "3a7bd5ff", and returns the most faithful foreground AttributeColor the
+// terminal supports, like BestColorFromHex. A true color is returned if
+// the terminal supports it, or else the nearest 256 or 16 color.
+func ParseHex(s string) (AttributeColor, error) {
+	c, err := ColorFromHex(s)
+	if err != nil {
+		return Default, err
+	}
+	r, g, b, _ := ToRGB(c)
+	return BestColor(r, g, b), nil
+}
+
 // BestBackgroundFromHex is the background-color variant of BestColorFromHex.
 // Returns DefaultBackground on parse error.
 func BestBackgroundFromHex(s string) AttributeColor {
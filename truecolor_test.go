@@ -134,13 +134,6 @@ func TestBlend(t *testing.T) {
 	}
 }
 
-func abs(x int) int {
-	if x < 0 {
-		return -x
-	}
-	return x
-}
-
 func TestLuminance(t *testing.T) {
 	// Black should have luminance 0
 	if l := Luminance(TrueColor(0, 0, 0)); l != 0 {
@@ -258,3 +251,87 @@ func TestBestColorFromHexNoColor(t *testing.T) {
 		t.Errorf("BestBackgroundFromHex with NO_COLOR: got %v, want DefaultBackground", got)
 	}
 }
+
+func TestRGB(t *testing.T) {
+	fg := RGB(0x3A, 0x7B, 0xD5)
+	if fg != TrueColor(0x3A, 0x7B, 0xD5) || fg.Background() != TrueBackground(0x3A, 0x7B, 0xD5) {
+		t.Errorf("unexpected RGB color: %x", uint32(fg))
+	}
+	c := NewCanvasWithSize(1, 1)
+	c.WriteRune(0, 0, fg, RGB(1, 2, 3), 'x')
+	if cr := c.chars[0]; cr.fg != fg || cr.bg != TrueBackground(1, 2, 3) {
+		t.Errorf("expected both true colors to be kept in the cell, got %+v", cr)
+	}
+}
+
+func TestColor256Helpers(t *testing.T) {
+	if got, want := Color256(42).String(), "\033[38;5;42m"; !EnvNoColor && got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := BackgroundColor256(42).String(), "\033[48;5;42m"; !EnvNoColor && got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := Color256(42).Combine(Reverse).String(), "\033[7m\033[38;5;42m"; !EnvNoColor && got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	for _, tc := range []struct {
+		r, g, b uint8
+		want    AttributeColor
+	}{
+		{0, 0, 0, Color256(16)},
+		{255, 255, 255, Color256(231)},
+		{0x3A, 0x7B, 0xD5, ColorCube(1, 2, 4)},
+		{100, 0, 0, ColorCube(1, 0, 0)},
+	} {
+		if got := Color256FromRGB(tc.r, tc.g, tc.b); got != tc.want {
+			t.Errorf("Color256FromRGB(%d, %d, %d): got %d, want %d", tc.r, tc.g, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestParseHex(t *testing.T) {
+	for _, tc := range []struct {
+		s       string
+		r, g, b uint8
+		ok      bool
+	}{
+		{"#3A7BD5", 0x3a, 0x7b, 0xd5, true},
+		{"#3a7bd5", 0x3a, 0x7b, 0xd5, true},
+		{"3A7BD5", 0x3a, 0x7b, 0xd5, true},
+		{"#F00", 0xff, 0, 0, true},
+		{"#f00", 0xff, 0, 0, true},
+		{"#3a7bd580", 0x3a, 0x7b, 0xd5, true},
+		{"", 0, 0, 0, false},
+		{"#12345", 0, 0, 0, false},
+		{"#xyz", 0, 0, 0, false},
+		{"#1234567890", 0, 0, 0, false},
+	} {
+		c, err := ParseHex(tc.s)
+		if (err == nil) != tc.ok {
+			t.Errorf("ParseHex(%q): unexpected error %v", tc.s, err)
+			continue
+		}
+		if !tc.ok {
+			continue
+		}
+		if want := BestColor(tc.r, tc.g, tc.b); c != want {
+			t.Errorf("ParseHex(%q) = %v, want %v", tc.s, c, want)
+		}
+	}
+}
+
+func TestAttributeColorHex(t *testing.T) {
+	for _, tc := range []struct {
+		c    AttributeColor
+		want string
+	}{
+		{RGB(0x3a, 0x7b, 0xd5), "#3a7bd5"},
+		{TrueBackground(1, 2, 3), "#010203"},
+		{Color256(196), "#ff0000"},
+		{Red, "#cd0000"},
+	} {
+		if got := tc.c.Hex(); got != tc.want {
+			t.Errorf("%v.Hex() = %q, want %q", tc.c, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package vt
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// openPTY opens a pseudo terminal and returns the master fd and the path
+// of the slave device
+func openPTY(t *testing.T) (int, string) {
+	master, err := unix.Open("/dev/ptmx", unix.O_RDWR|unix.O_NOCTTY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		t.Skip(err)
+	}
+	t.Cleanup(func() { unix.Close(master) })
+	if err := unix.IoctlSetPointerInt(master, unix.TIOCSPTLCK, 0); err != nil {
+		t.Skip(err)
+	}
+	n, err := unix.IoctlGetUint32(master, unix.TIOCGPTN)
+	if err != nil {
+		t.Skip(err)
+	}
+	return master, "/dev/pts/" + strconv.FormatUint(uint64(n), 10)
+}
+
+func TestTTYLibraryAndAppRestoreOriginalState(t *testing.T) {
+	_, path := openPTY(t)
+	fd, err := unix.Open(path, unix.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer unix.Close(fd)
+	before, err := tcgetattr(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A library opens a TTY, then the application opens its own, and the
+	// library is done first
+	lib, err := openTTY(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, err := openTTY(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lib.Close()
+	if during, _ := tcgetattr(fd); during.Lflag&unix.ICANON != 0 {
+		t.Error("closing the first TTY should leave the terminal in raw mode for the second one")
+	}
+	app.Close()
+	app.Close() // closing twice is harmless
+
+	after, err := tcgetattr(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after != before {
+		t.Errorf("terminal state was not restored:\nbefore %+v\nafter  %+v", before, after)
+	}
+}
+
+func TestQueryMode(t *testing.T) {
+	master, path := openPTY(t)
+	tty, err := openTTY(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tty.Close()
+
+	reply := func(s string) {
+		buf := make([]byte, 64)
+		n, err := unix.Read(master, buf)
+		if err != nil || !strings.HasSuffix(string(buf[:n]), "$p") {
+			t.Errorf("expected a DECRQM query, got %q (%v)", buf[:n], err)
+		}
+		unix.Write(master, []byte(s))
+	}
+
+	go reply("\033[?2004;2$y")
+	if supported, set, err := tty.QueryMode(2004); err != nil || !supported || set {
+		t.Errorf("got (%v, %v, %v), want (true, false, nil)", supported, set, err)
+	}
+	go reply("\033[?1006;0$y")
+	if supported, set, err := tty.QueryMode(1006); err != nil || supported || set {
+		t.Errorf("got (%v, %v, %v), want (false, false, nil)", supported, set, err)
+	}
+}
+
+func TestRestoreTerminalClosesOpenTTYs(t *testing.T) {
+	_, path := openPTY(t)
+	fd, err := unix.Open(path, unix.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer unix.Close(fd)
+	before, err := tcgetattr(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tty, err := openTTY(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restoreTerminal()
+	if !tty.closed {
+		t.Error("expected the open TTY to be closed")
+	}
+	if after, _ := tcgetattr(fd); after != before {
+		t.Errorf("terminal state was not restored:\nbefore %+v\nafter  %+v", before, after)
+	}
+	openTTYsMut.Lock()
+	defer openTTYsMut.Unlock()
+	if _, ok := openTTYs[tty]; ok {
+		t.Error("expected the closed TTY to be unregistered")
+	}
+}
@@ -0,0 +1,40 @@
+package vt
+
+import "sync"
+
+// sharedState holds the terminal state from before the first TTY was
+// opened. When several TTYs are open at the same time (for instance one
+// opened by a library and one by the application), they all share this
+// state, so that closing one of them does not restore a state that was
+// captured after another TTY had already changed the terminal, and only
+// the last Close restores the terminal. Reading from more than one TTY at
+// the same time is not supported.
+type sharedState[T any] struct {
+	mut   sync.Mutex
+	count int
+	orig  T
+}
+
+// acquire registers an open TTY. If it is the first one, current is stored
+// as the original state. The original state is returned.
+func (s *sharedState[T]) acquire(current T) T {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if s.count == 0 {
+		s.orig = current
+	}
+	s.count++
+	return s.orig
+}
+
+// release unregisters an open TTY, and returns true if it was the last one,
+// meaning that the original state should now be restored
+func (s *sharedState[T]) release() bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if s.count == 0 {
+		return false
+	}
+	s.count--
+	return s.count == 0
+}
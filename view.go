@@ -0,0 +1,192 @@
+package vt
+
+import "errors"
+
+// CanvasView is a rectangular part of a Canvas, with its own coordinate
+// system, see SubCanvas. Writes go straight to the cells of the parent
+// canvas, under the parent's mutex, and are discarded if they are outside
+// of the view or outside of the clip rectangle of the parent.
+type CanvasView struct {
+	parent     *Canvas
+	x, y, w, h uint
+	generation uint // the generation of the parent cells that the view is for
+}
+
+// SubCanvas returns a view of the w x h rectangle of the canvas with its
+// top left corner at (x, y), so that widgets can draw into their own part
+// of the canvas with (0, 0) as their top left corner, without copying any
+// cells. The rectangle is clipped to the canvas, and the padding area is
+// not taken into account. When the canvas is resized to another size, the
+// view becomes empty: its size is 0x0 and all writes are discarded.
+func (c *Canvas) SubCanvas(x, y, w, h uint) *CanvasView {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	if x >= c.w || y >= c.h {
+		w, h = 0, 0
+	}
+	return &CanvasView{c, x, y, umin(w, c.w-umin(x, c.w)), umin(h, c.h-umin(y, c.h)), c.generation}
+}
+
+// Parent returns the canvas that the view is a part of
+func (v *CanvasView) Parent() *Canvas {
+	return v.parent
+}
+
+// SubCanvas returns a view of the w x h rectangle of the view with its top
+// left corner at (x, y), clipped to the view. The new view writes to the
+// same parent canvas, so that a widget can hand part of its own area on to
+// a child widget.
+func (v *CanvasView) SubCanvas(x, y, w, h uint) *CanvasView {
+	v.parent.mut.RLock()
+	defer v.parent.mut.RUnlock()
+	if v.stale() || x >= v.w || y >= v.h {
+		return &CanvasView{v.parent, v.x, v.y, 0, 0, v.generation}
+	}
+	return &CanvasView{v.parent, v.x + x, v.y + y, umin(w, v.w-x), umin(h, v.h-y), v.generation}
+}
+
+// stale reports if the parent has been resized since the view was made.
+// The parent mutex must be held.
+func (v *CanvasView) stale() bool {
+	return v.generation != v.parent.generation
+}
+
+// index returns the index of (x, y) of the view in the cells of the
+// parent, and false if (x, y) may not be written to. The parent mutex must
+// be held.
+func (v *CanvasView) index(x, y uint) (uint, bool) {
+	if v.stale() || x >= v.w || y >= v.h {
+		return 0, false
+	}
+	px, py := v.x+x, v.y+y
+	return py*v.parent.w + px, v.parent.inClip(px, py)
+}
+
+// Size returns the width and height of the view
+func (v *CanvasView) Size() (uint, uint) {
+	v.parent.mut.RLock()
+	defer v.parent.mut.RUnlock()
+	if v.stale() {
+		return 0, 0
+	}
+	return v.w, v.h
+}
+
+// W returns the width of the view
+func (v *CanvasView) W() uint {
+	w, _ := v.Size()
+	return w
+}
+
+// H returns the height of the view
+func (v *CanvasView) H() uint {
+	_, h := v.Size()
+	return h
+}
+
+// At returns the rune at (x, y) of the view
+func (v *CanvasView) At(x, y uint) (rune, error) {
+	v.parent.mut.RLock()
+	defer v.parent.mut.RUnlock()
+	if v.stale() || x >= v.w || y >= v.h {
+		return rune(0), errors.New("out of bounds")
+	}
+	return v.parent.chars[(v.y+y)*v.parent.w+v.x+x].r, nil
+}
+
+// Plot sets the rune at (x, y) of the view
+func (v *CanvasView) Plot(x, y uint, r rune) {
+	v.parent.mut.Lock()
+	defer v.parent.mut.Unlock()
+	if i, ok := v.index(x, y); ok {
+		v.parent.chars[i].r = r
+		v.parent.chars[i].drawn = false
+	}
+}
+
+// PlotColor sets the rune and foreground color at (x, y) of the view
+func (v *CanvasView) PlotColor(x, y uint, fg AttributeColor, r rune) {
+	v.parent.mut.Lock()
+	defer v.parent.mut.Unlock()
+	if i, ok := v.index(x, y); ok {
+		v.parent.chars[i].r = r
+		v.parent.chars[i].fg = fg
+		v.parent.chars[i].drawn = false
+	}
+}
+
+// WriteRune writes a colored rune at (x, y) of the view
+func (v *CanvasView) WriteRune(x, y uint, fg, bg AttributeColor, r rune) {
+	v.parent.mut.Lock()
+	defer v.parent.mut.Unlock()
+	if i, ok := v.index(x, y); ok {
+		v.parent.chars[i] = ColorRune{fg: fg, bg: bg.Background(), r: r}
+	}
+}
+
+// WriteBackground sets the background color at (x, y) of the view
+func (v *CanvasView) WriteBackground(x, y uint, bg AttributeColor) {
+	v.parent.mut.Lock()
+	defer v.parent.mut.Unlock()
+	if i, ok := v.index(x, y); ok {
+		v.parent.chars[i].bg = bg
+		v.parent.chars[i].drawn = false
+	}
+}
+
+// WriteString writes s at (x, y) of the view, clipping at the right edge
+// of the view. Wide runes take up two cells, and a wide rune that does not
+// fit at the end of the row is replaced with a space.
+func (v *CanvasView) WriteString(x, y uint, fg, bg AttributeColor, s string) {
+	bgb := bg.Background()
+	v.parent.mut.Lock()
+	defer v.parent.mut.Unlock()
+	if v.stale() || y >= v.h {
+		return
+	}
+	for _, r := range s {
+		if x >= v.w {
+			break
+		}
+		rw := runeWidth(r)
+		if rw == 0 {
+			continue
+		}
+		if rw == 2 {
+			if x+1 < v.w {
+				v.parent.WriteWideRuneBNoLock(v.x+x, v.y+y, fg, bgb, r)
+				x += 2
+				continue
+			}
+			r = ' ' // only half of the wide rune would fit
+		}
+		if i, ok := v.index(x, y); ok {
+			v.parent.chars[i] = ColorRune{fg: fg, bg: bgb, r: r}
+		}
+		x++
+	}
+}
+
+// FillRect fills the w x h rectangle of the view with its top left corner
+// at (x, y) with r, in the given colors, clipping at the edges of the view
+func (v *CanvasView) FillRect(x, y, w, h uint, fg, bg AttributeColor, r rune) {
+	v.parent.mut.Lock()
+	defer v.parent.mut.Unlock()
+	for j := y; j < umin(y+h, v.h); j++ {
+		for k := x; k < umin(x+w, v.w); k++ {
+			if i, ok := v.index(k, j); ok {
+				v.parent.chars[i] = ColorRune{fg: fg, bg: bg.Background(), r: r}
+			}
+		}
+	}
+}
+
+// Clear fills the view with spaces in the default colors
+func (v *CanvasView) Clear() {
+	v.FillRect(0, 0, v.w, v.h, Default, DefaultBackground, ' ')
+}
+
+// Draw draws the parent canvas, which includes the view
+func (v *CanvasView) Draw() bool {
+	return v.parent.Draw()
+}
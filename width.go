@@ -0,0 +1,122 @@
+package vt
+
+import "unicode"
+
+// wideRanges holds the East Asian Wide and Fullwidth ranges, plus the
+// common emoji blocks, that terminals render as two columns
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F},   // Hangul Jamo initial consonants
+	{0x231A, 0x231B},   // watch, hourglass
+	{0x2329, 0x232A},   // angle brackets
+	{0x23E9, 0x23EC},   // media control symbols
+	{0x23F0, 0x23F0},   // alarm clock
+	{0x23F3, 0x23F3},   // hourglass with flowing sand
+	{0x25FD, 0x25FE},   // medium small squares
+	{0x2614, 0x2615},   // umbrella, hot beverage
+	{0x2648, 0x2653},   // zodiac signs
+	{0x267F, 0x267F},   // wheelchair symbol
+	{0x2693, 0x2693},   // anchor
+	{0x26A1, 0x26A1},   // high voltage
+	{0x26AA, 0x26AB},   // medium circles
+	{0x26BD, 0x26BE},   // soccer ball, baseball
+	{0x26C4, 0x26C5},   // snowman, sun behind cloud
+	{0x26CE, 0x26CE},   // ophiuchus
+	{0x26D4, 0x26D4},   // no entry
+	{0x26EA, 0x26EA},   // church
+	{0x26F2, 0x26F3},   // fountain, flag in hole
+	{0x26F5, 0x26F5},   // sailboat
+	{0x26FA, 0x26FA},   // tent
+	{0x26FD, 0x26FD},   // fuel pump
+	{0x2705, 0x2705},   // check mark button
+	{0x270A, 0x270B},   // raised fist, raised hand
+	{0x2728, 0x2728},   // sparkles
+	{0x274C, 0x274C},   // cross mark
+	{0x274E, 0x274E},   // cross mark button
+	{0x2753, 0x2755},   // question and exclamation marks
+	{0x2757, 0x2757},   // exclamation mark
+	{0x2795, 0x2797},   // plus, minus, divide
+	{0x27B0, 0x27B0},   // curly loop
+	{0x27BF, 0x27BF},   // double curly loop
+	{0x2B1B, 0x2B1C},   // large squares
+	{0x2B50, 0x2B50},   // star
+	{0x2B55, 0x2B55},   // large circle
+	{0x2E80, 0x303E},   // CJK radicals, punctuation
+	{0x3041, 0x33FF},   // Hiragana, Katakana, CJK compatibility
+	{0x3400, 0x4DBF},   // CJK Unified Ideographs Extension A
+	{0x4E00, 0x9FFF},   // CJK Unified Ideographs
+	{0xA000, 0xA4CF},   // Yi
+	{0xA960, 0xA97F},   // Hangul Jamo Extended-A
+	{0xAC00, 0xD7A3},   // Hangul Syllables
+	{0xF900, 0xFAFF},   // CJK Compatibility Ideographs
+	{0xFE10, 0xFE19},   // vertical forms
+	{0xFE30, 0xFE6F},   // CJK compatibility forms, small form variants
+	{0xFF00, 0xFF60},   // fullwidth forms
+	{0xFFE0, 0xFFE6},   // fullwidth signs
+	{0x1F004, 0x1F004}, // mahjong tile
+	{0x1F0CF, 0x1F0CF}, // playing card
+	{0x1F18E, 0x1F18E}, // AB button
+	{0x1F191, 0x1F19A}, // squared words
+	{0x1F200, 0x1F251}, // enclosed ideographic supplement
+	{0x1F300, 0x1F64F}, // misc symbols and pictographs, emoticons
+	{0x1F680, 0x1F6FF}, // transport and map symbols
+	{0x1F7E0, 0x1F7EB}, // colored circles and squares
+	{0x1F90C, 0x1F9FF}, // supplemental symbols and pictographs
+	{0x1FA70, 0x1FAFF}, // symbols and pictographs extended-A
+	{0x20000, 0x2FFFD}, // CJK Unified Ideographs Extension B and beyond
+	{0x30000, 0x3FFFD}, // CJK Unified Ideographs Extension G and beyond
+}
+
+// runeWidth returns the number of terminal columns r occupies:
+// 0 for control characters and combining marks, 2 for wide (CJK, emoji)
+// runes and 1 for everything else.
+func runeWidth(r rune) int {
+	if r < 0x20 || (r >= 0x7F && r < 0xA0) {
+		return 0
+	}
+	if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	if r < 0x1100 {
+		return 1
+	}
+	for _, wr := range wideRanges {
+		if r < wr[0] {
+			break
+		}
+		if r <= wr[1] {
+			return 2
+		}
+	}
+	return 1
+}
+
+// VisibleWidth returns the number of terminal columns s occupies. Escape
+// sequences, like colors, take up no columns, wide runes take up two and
+// combining marks none, the same as when writing s to a Canvas.
+func VisibleWidth(s string) int {
+	width := 0
+	for _, r := range StripColors(s) {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// writeColumns writes s at (x, y), with wide runes taking up two columns.
+// Combining marks and control characters are skipped, since they have no
+// cell of their own. Returns the number of columns that s takes up.
+func (c *Canvas) writeColumns(x, y uint, fg, bg AttributeColor, s string) uint {
+	col := x
+	for _, r := range s {
+		rw := runeWidth(r)
+		switch rw {
+		case 0:
+			continue
+		case 2:
+			c.WriteString(col, y, fg, bg, string(r))
+		default:
+			c.WriteRune(col, y, fg, bg, r)
+		}
+		col += uint(rw)
+	}
+	return col - x
+}
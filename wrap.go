@@ -0,0 +1,272 @@
+package vt
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// truncate shortens s to at most width columns, ending it with '…' if
+// anything had to be cut. Wide runes count as two columns.
+func truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	total := 0
+	for _, r := range s {
+		total += runeWidth(r)
+	}
+	if total <= width {
+		return s
+	}
+	var sb strings.Builder
+	used := 0
+	for _, r := range s {
+		rw := runeWidth(r)
+		if used+rw > width-1 {
+			break
+		}
+		sb.WriteRune(r)
+		used += rw
+	}
+	return sb.String() + "…"
+}
+
+// wrapText word-wraps s into lines of at most width columns. Existing
+// newlines are kept, and words longer than width are split. Wide runes
+// count as two columns.
+func wrapText(s string, width int) []string {
+	return wrapLines(s, width, false)
+}
+
+// wrapLines is wrapText, but if keepIndent is true, the leading spaces of
+// each paragraph are kept on its first line
+func wrapLines(s string, width int, keepIndent bool) []string {
+	if width <= 0 {
+		return nil
+	}
+	var lines []string
+	for paragraph := range strings.SplitSeq(s, "\n") {
+		var line []rune
+		lineWidth, words := 0, 0
+		if keepIndent {
+			rest := strings.TrimLeft(paragraph, " ")
+			if indent := min(len(paragraph)-len(rest), width-1); indent > 0 {
+				line, lineWidth = []rune(strings.Repeat(" ", indent)), indent
+			}
+		}
+		newline := func() {
+			lines = append(lines, string(line))
+			line, lineWidth, words = line[:0], 0, 0
+		}
+		for _, word := range strings.Fields(paragraph) {
+			w := []rune(word)
+			ww := 0
+			for _, r := range w {
+				ww += runeWidth(r)
+			}
+			if words > 0 {
+				if lineWidth+1+ww <= width {
+					line = append(line, ' ')
+					line, lineWidth, words = append(line, w...), lineWidth+1+ww, words+1
+					continue
+				}
+				newline()
+			}
+			for lineWidth+ww > width {
+				// Split words that are longer than a line
+				n, used := 0, 0
+				for n < len(w) && lineWidth+used+runeWidth(w[n]) <= width {
+					used += runeWidth(w[n])
+					n++
+				}
+				if n == 0 && lineWidth == 0 {
+					// A wide rune does not fit at all, so let it stick out
+					n, used = 1, runeWidth(w[0])
+				}
+				line = append(line, w[:n]...)
+				newline()
+				w, ww = w[n:], ww-used
+			}
+			if len(w) > 0 {
+				line, lineWidth, words = append(line, w...), lineWidth+ww, 1
+			}
+		}
+		lines = append(lines, string(line))
+	}
+	return lines
+}
+
+// RenderMarkup replaces the color tags in s, like <red> and <off>, and
+// word-wraps the result into lines of at most width columns, in the same
+// way as WriteStringWrapped. Escape sequences take up no columns. Colors
+// that are still on at the end of a line are reset there and turned on
+// again at the start of the next line, so that each line can be written on
+// its own. Tags are removed without adding colors if NO_COLOR is set.
+func RenderMarkup(s string, width uint) []string {
+	return wrapEscaped(New().Tags(s), int(width))
+}
+
+// wrapEscaped is like wrapText, but for text that may contain escape
+// sequences, which are kept and are not counted toward the width
+func wrapEscaped(s string, width int) []string {
+	if width <= 0 {
+		return nil
+	}
+	var (
+		lines     []string
+		line      strings.Builder
+		lineWidth int
+		active    string // SGR sequences in effect since the last reset
+	)
+	newline := func() {
+		if active != "" {
+			line.WriteString(NoColor)
+		}
+		lines = append(lines, line.String())
+		line.Reset()
+		line.WriteString(active)
+		lineWidth = 0
+	}
+	for paragraph := range strings.SplitSeq(s, "\n") {
+		for _, word := range strings.Fields(paragraph) {
+			if ww := VisibleWidth(word); ww > 0 && lineWidth > 0 {
+				if lineWidth+1+ww <= width {
+					line.WriteByte(' ')
+					lineWidth++
+				} else {
+					newline()
+				}
+			}
+			for i := 0; i < len(word); {
+				if word[i] == '\033' {
+					j := escapeEnd(word, i)
+					esc := word[i:j]
+					if strings.HasPrefix(esc, "\033[") && strings.HasSuffix(esc, "m") {
+						if params := esc[2 : len(esc)-1]; params == "" || params == "0" {
+							active = ""
+						} else {
+							active += esc
+						}
+					}
+					line.WriteString(esc)
+					i = j
+					continue
+				}
+				r, size := utf8.DecodeRuneInString(word[i:])
+				rw := runeWidth(r)
+				if lineWidth > 0 && lineWidth+rw > width {
+					// Split words that are longer than a line
+					newline()
+				}
+				line.WriteString(word[i : i+size])
+				lineWidth += rw
+				i += size
+			}
+		}
+		newline()
+	}
+	return lines
+}
+
+// WriteStringWrapped writes s at (x, y), word-wrapped to lines of at most
+// maxWidth columns, or up to the right edge of the canvas if that comes
+// first. Words that are longer than a line are split, and '\n' starts a
+// new line. Writing stops at the bottom of the canvas. Returns the number
+// of rows that were written, so that paragraphs can be stacked.
+func (c *Canvas) WriteStringWrapped(x, y, maxWidth uint, fg, bg AttributeColor, s string) (linesUsed uint) {
+	w, h := c.Size()
+	if x >= w || y >= h {
+		return 0
+	}
+	for _, line := range wrapText(s, int(umin(maxWidth, w-x))) {
+		if y+linesUsed >= h {
+			break
+		}
+		c.WriteString(x, y+linesUsed, fg, bg, line)
+		linesUsed++
+	}
+	return linesUsed
+}
+
+// WriteText writes s as a block of text at (x, y), word-wrapped to lines
+// of at most maxW columns, or up to the right edge of the canvas if that
+// comes first. '\n' starts a new paragraph, and the leading spaces of a
+// paragraph are kept, so that it can be indented. At most maxH lines are
+// written, and writing stops at the bottom of the canvas. Returns the
+// number of lines that were written.
+func (c *Canvas) WriteText(x, y, maxW, maxH uint, fg, bg AttributeColor, s string) (linesWritten uint) {
+	w, h := c.Size()
+	if x >= w || y >= h {
+		return 0
+	}
+	maxH = umin(maxH, h-y)
+	for _, line := range wrapLines(s, int(umin(maxW, w-x)), true) {
+		if linesWritten >= maxH {
+			break
+		}
+		c.WriteString(x, y+linesWritten, fg, bg, line)
+		linesWritten++
+	}
+	return linesWritten
+}
+
+// MeasureText returns the number of columns and rows that s takes up when
+// it is written with WriteText and a maximum width of maxW, without
+// limiting the height or clipping at the edges of the canvas
+func (c *Canvas) MeasureText(maxW uint, s string) (cols, rows uint) {
+	lines := wrapLines(s, int(maxW), true)
+	for _, line := range lines {
+		cols = max(cols, uint(VisibleWidth(line)))
+	}
+	return cols, uint(len(lines))
+}
+
+// Align is the horizontal alignment of text written with DrawText
+type Align int
+
+// Text alignments
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+// DrawText writes s into the width columns that start at (x, y), aligned
+// within them according to align. The rest of the columns are filled with
+// spaces in the bg color, and s is truncated with '…' if it is too wide.
+// Escape sequences in s are removed, and wide runes take up two columns.
+// Nothing is written past x+width.
+func (c *Canvas) DrawText(x, y, width uint, fg, bg AttributeColor, s string, align Align) {
+	if width == 0 {
+		return
+	}
+	s = truncate(StripColors(s), int(width))
+	pad := width - uint(VisibleWidth(s))
+	var left uint
+	switch align {
+	case AlignCenter:
+		left = pad / 2
+	case AlignRight:
+		left = pad
+	}
+	c.WriteString(x, y, fg, bg, strings.Repeat(" ", int(left))+s+strings.Repeat(" ", int(pad-left)))
+}
+
+// WriteCentered writes s centered on row y. Wide runes count as two
+// columns and combining marks as none. A string that is wider than the
+// canvas is truncated with '…'.
+func (c *Canvas) WriteCentered(y uint, fg, bg AttributeColor, s string) {
+	w := c.W()
+	s = truncate(s, int(w))
+	c.WriteString((w-uint(VisibleWidth(s)))/2, y, fg, bg, s)
+}
+
+// WriteRightAligned writes s on row y, so that it ends just before column
+// rightX. Pass the width of the canvas to align s with the right edge. Wide
+// runes count as two columns and combining marks as none. A string that
+// does not fit is truncated with '…'.
+func (c *Canvas) WriteRightAligned(y, rightX uint, fg, bg AttributeColor, s string) {
+	rightX = umin(rightX, c.W())
+	s = truncate(s, int(rightX))
+	c.WriteString(rightX-uint(VisibleWidth(s)), y, fg, bg, s)
+}